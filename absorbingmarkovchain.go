@@ -3,50 +3,111 @@ package absorbingmarkovchain
 
 import (
 	"context"
-	"io/ioutil"
-	"math"
+	"fmt"
 	"math/rand"
-	"os"
-	"path/filepath"
 	"runtime/debug"
+	"sort"
 
 	"github.com/RoaringBitmap/roaring"
 	"github.com/pkg/errors"
-
-	"github.com/ebonetti/absorbingmarkovchain/internal/gmres"
 )
 
-// New creates a new absorbing markov chain.
-func New(tmpDir string, nodes, absorbingNodes *roaring.Bitmap, edges func(from uint32) (to []uint32), weighter func(from, to uint32) (weight float64, err error)) *AbsorbingMarkovChain {
-	return &AbsorbingMarkovChain{
-		wDGraph{
+// New creates a new absorbing markov chain, configured with the given options.
+func New(tmpDir string, nodes, absorbingNodes *roaring.Bitmap, edges func(from uint32) (to []uint32), weighter func(from, to uint32) (weight float64, err error), options ...Option) *AbsorbingMarkovChain {
+	chain := &AbsorbingMarkovChain{
+		wDGraph: wDGraph{
 			dGraph{
 				nodes,
 				edges,
 			},
 			weighter,
 		},
-		absorbingNodes,
-		tmpDir,
+		absorbingNodes: absorbingNodes,
+		tmpDir:         tmpDir,
+	}
+	for _, o := range options {
+		o(chain)
+	}
+	return chain
+}
+
+// NewInferred creates a new absorbing markov chain, configured with the given options, whose absorbing nodes are
+// inferred from the edges: nodes without arcs or with only a self loop. With the WithClosedClasses option, also the nodes
+// of closed classes, that is strongly connected components without arcs leaving them, are made absorbing by dropping their arcs.
+func NewInferred(tmpDir string, nodes *roaring.Bitmap, edges func(from uint32) (to []uint32), weighter func(from, to uint32) (weight float64, err error), options ...Option) *AbsorbingMarkovChain {
+	chain := New(tmpDir, nodes, roaring.NewBitmap(), edges, weighter, options...)
+
+	for i := nodes.Iterator(); i.HasNext(); {
+		from := i.Next()
+		to := edges(from)
+		switch {
+		case len(to) == 0:
+			fallthrough
+		case len(to) == 1 && from == to[0]:
+			chain.absorbingNodes.Add(from)
+		}
 	}
+
+	if chain.closedClasses {
+		closed := chain.dGraph.closedClasses()
+		chain.absorbingNodes.Or(closed)
+		chain.Edges = func(from uint32) (to []uint32) {
+			if closed.Contains(from) {
+				return nil
+			}
+			return edges(from)
+		}
+	}
+
+	return chain
 }
 
 // AbsorbingMarkovChain represents an absorbing markov chain.
 type AbsorbingMarkovChain struct {
 	wDGraph
-	absorbingNodes *roaring.Bitmap
-	tmpDir         string
+	absorbingNodes    *roaring.Bitmap
+	tmpDir            string
+	solver            Solver
+	solverOptions     SolverOptions
+	tieBreaking       TieBreaking
+	rand              *rand.Rand
+	closedClasses     bool
+	unreachablePolicy UnreachablePolicy
+	unreachable       *roaring.Bitmap //set by checkRequirements
+	targets           *roaring.Bitmap
+	pruning           pruning
+	artifactsDir      string
 }
 
-// AbsorptionProbabilities calculates absorption probabilities for the current absorbing markov chain.
-func (chain *AbsorbingMarkovChain) AbsorptionProbabilities(ctx context.Context) (weighter func(from, to uint32) (weight float64, err error), err error) {
-	fuzzyAssignments, ttn, tan, err := chain.absorptionProbabilities(ctx, func() { chain = nil }) //enable eventual GC
+// AbsorptionProbabilities calculates absorption probabilities for the current absorbing markov chain,
+// along with a report of the solve for each target absorbing node. If any of those solves diverges, a *DivergenceError is returned.
+func (chain *AbsorbingMarkovChain) AbsorptionProbabilities(ctx context.Context) (weighter func(from, to uint32) (weight float64, err error), report SolveReport, err error) {
+	results, err := chain.AbsorptionResults(ctx)
 	if err != nil {
 		return
 	}
 
-	return func(from, to uint32) (weight float64, err error) {
-		a, e1 := tan.ToNew(to)
+	return results.Weight, results.Report, nil
+}
+
+// GroupAbsorptionProbabilities calculates, for the current absorbing markov chain, the probabilities of being absorbed
+// in each group of absorbing nodes, given as a map from absorbing node to group ID: a single system is solved for
+// each group. Absorbing nodes outside groups are not part of any group, and WithTargets is ignored.
+// The report of the solves is keyed by group ID.
+func (chain *AbsorbingMarkovChain) GroupAbsorptionProbabilities(ctx context.Context, groups map[uint32]uint32) (weighter func(from, group uint32) (weight float64, err error), report SolveReport, err error) {
+	if len(groups) == 0 {
+		err = errors.New("AbsorbingMarkovChain Error: no groups")
+		return
+	}
+
+	results, err := chain.absorptionProbabilities(ctx, nil, groups, func() { chain = nil }) //enable eventual GC
+	if err != nil {
+		return
+	}
+
+	fuzzyAssignments, ttn, tgn := results.probabilities, results.ttn, results.tan
+	return func(from, group uint32) (weight float64, err error) {
+		g, e1 := tgn.ToNew(group)
 		t, e2 := ttn.ToNew(from)
 		switch {
 		case e1 != nil:
@@ -54,196 +115,168 @@ func (chain *AbsorbingMarkovChain) AbsorptionProbabilities(ctx context.Context)
 		case e2 != nil:
 			err = e2
 		default:
-			weight = fuzzyAssignments[a][t]
+			weight = fuzzyAssignments.at(int(g), int(t))
 		}
 		return
-	}, nil
+	}, results.Report, nil
 }
 
 // AbsorptionAssignments calculates a majority assignment from absorption probabilities.
+// Ties are broken according to the TieBreaking policy of the chain: with TieAmbiguous, tied transient nodes are left
+// unassigned and an *AmbiguityError reporting them is returned along with the assigner. With WithPruning, only the
+// retained probabilities are compared, and transient nodes without any are left unassigned.
 func (chain *AbsorbingMarkovChain) AbsorptionAssignments(ctx context.Context) (assigner map[uint32]uint32, err error) {
-	fail := func(e error) (map[uint32]uint32, error) {
-		assigner, err = nil, e
-		return assigner, err
-	}
+	tieBreaking, rnd := chain.tieBreaking, chain.rand
 
-	fuzzyAssignments, ttn, tan, err := chain.absorptionProbabilities(ctx, func() { chain = nil }) //enable eventual GC
+	results, err := chain.absorptionProbabilities(ctx, nil, nil, func() { chain = nil }) //enable eventual GC
 	if err != nil {
-		return fail(err)
+		return nil, err
 	}
 
-	silentFail := func(fi func(uint32) (uint32, error)) func(int) uint32 {
-		return func(intida int) (idb uint32) {
-			ida := uint32(intida)
-			switch {
-			case err != nil:
-				//Skip it
-			case ida > ^uint32(0): //max Uint32
-				err = errors.Errorf("%v is not a valid node.", ida)
-			default:
-				idb, err = fi(ida)
-			}
-			return
-		}
-	}
-	ttn2Old := silentFail(ttn.ToOld)
-	tan2Old := silentFail(tan.ToOld)
-
-	assigner = make(map[uint32]uint32, len(fuzzyAssignments[0]))
-	for tnID := range fuzzyAssignments[0] {
-		perm := rand.Perm(len(fuzzyAssignments))
-		bestv, bestw := -1, -1.0
-		for _, v := range perm {
-			w := fuzzyAssignments[v][tnID]
-			if w > bestw {
-				bestv = v
-				bestw = w
-			}
-		}
-		assigner[ttn2Old(tnID)] = tan2Old(bestv)
+	return results.Assignments(tieBreaking, rnd)
+}
+
+// AbsorptionTopK calculates, for each transient node, the k absorbing nodes with highest absorption probabilities,
+// sorted by decreasing probability and, in case of ties, by increasing ID. With WithPruning, only the retained
+// probabilities are ranked.
+func (chain *AbsorbingMarkovChain) AbsorptionTopK(ctx context.Context, k int) (topK map[uint32][]NodeWeight, err error) {
+	if k < 1 {
+		return nil, errors.Errorf("AbsorbingMarkovChain Error: %v is not a valid k.", k)
 	}
 
+	results, err := chain.absorptionProbabilities(ctx, nil, nil, func() { chain = nil }) //enable eventual GC
 	if err != nil {
-		return fail(err)
+		return nil, err
 	}
 
-	return
+	return results.TopK(k)
 }
 
-func (chain *AbsorbingMarkovChain) absorptionProbabilities(ctx context.Context, clean func()) (fuzzyAssignments [][]float64, ttn, tan translator, err error) {
-	fail := func(e error) ([][]float64, translator, translator, error) {
-		fuzzyAssignments, ttn, tan, err = nil, nil, nil, e
-		return fuzzyAssignments, ttn, tan, err
-	}
-
-	if err = chain.checkRequirements(); err != nil {
-		return fail(err)
+// AbsorbedBy calculates the k transient nodes with highest absorption probabilities into absorbingNode, sorted by
+// decreasing probability and, in case of ties, by increasing ID. Only the right hand side of absorbingNode is solved.
+func (chain *AbsorbingMarkovChain) AbsorbedBy(ctx context.Context, absorbingNode uint32, k int) (topK []NodeWeight, err error) {
+	fail := func(e error) ([]NodeWeight, error) {
+		topK, err = nil, e
+		return topK, err
 	}
 
-	var tmpDir string
-	if tmpDir, err = ioutil.TempDir(chain.tmpDir, "."); err != nil {
-		return fail(errors.Wrap(err, "AbsorbingMarkovChain Error: unable to create a temporary directory."))
+	if k < 1 {
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: %v is not a valid k.", k))
 	}
-	defer os.RemoveAll(tmpDir)
-	solverInfile := filepath.Join(tmpDir, "Ab.ptsc")
-	solverOutfile := filepath.Join(tmpDir, "sol.matlab")
 
-	//transform wikigraph to Ab.petsc
-	if ttn, tan, err = graph2Petsc(chain, solverInfile); err != nil {
+	results, err := chain.absorptionProbabilities(ctx, roaring.BitmapOf(absorbingNode), nil, func() { chain = nil }) //enable eventual GC
+	if err != nil {
 		return fail(err)
 	}
 
-	//enable eventual GC
-	chain = nil
-	clean()
-	debug.FreeOSMemory()
-
-	//run solver
-	if err = gmres.Run(ctx, solverInfile, solverOutfile, tmpDir); err != nil {
-		return fail(err)
+	topK = make([]NodeWeight, results.probabilities.nT)
+	for tnID := range topK {
+		tn, err := results.ttn.ToOld(uint32(tnID))
+		if err != nil {
+			return fail(err)
+		}
+		topK[tnID] = NodeWeight{tn, results.probabilities.at(0, tnID)}
 	}
-
-	//transform back from sol.matlab
-	if fuzzyAssignments, err = petsc2Assignments(ttn, tan, solverOutfile); err != nil {
-		return fail(err)
+	sort.SliceStable(topK, func(i, j int) bool { return topK[i].Weight > topK[j].Weight }) //IDs are already increasing
+	if len(topK) > k {
+		topK = topK[:k]
 	}
 
 	return
 }
 
-func (chain *AbsorbingMarkovChain) checkRequirements() (err error) { //for absorbing markov chain
-	if chain == nil {
-		return errors.New("AbsorbingMarkovChain Error: nil chain")
+// absorptionProbabilities solves the chain for the target absorbing nodes, the ones of the chain if targets is nil,
+// or for the groups of absorbing nodes if groups is not nil; tan then translates group IDs.
+func (chain *AbsorbingMarkovChain) absorptionProbabilities(ctx context.Context, targets *roaring.Bitmap, groups map[uint32]uint32, clean func()) (results *Results, err error) {
+	if targets == nil && chain != nil {
+		targets = chain.targets
 	}
 
-	if err = chain.checkGraphNodes(); err == nil {
-		return
+	if chain, err = chain.checkRequirements(); err != nil {
+		return nil, err
 	}
 
-	nodes := roaring.NewBitmap()
-
-	if err = chain.checkAbsorbingNodes(nodes); err == nil {
-		return
+	//transform wikigraph to the linear system
+	A, R, ttn, tan, err := graph2System(chain, targets, groups)
+	if err != nil {
+		return nil, err
 	}
-
-	if err = chain.checkTransientNodes(nodes); err == nil {
-		return
+	system := chain.linearSystem(A, ttn)
+	pr, n := chain.pruning, A.Size()
+	batch := pr.batchSize(len(R))
+	results = &Results{
+		Report: SolveReport{Stats: make(map[uint32]SolveStats, len(R)), Unreachable: chain.unreachable},
+		Metadata: Metadata{
+			SolverOptions:  chain.solverOptions.withDefaults(),
+			Nodes:          chain.Nodes.GetCardinality(),
+			AbsorbingNodes: chain.assignableNodes().GetCardinality(),
+			SystemHash:     systemHash(A, R, ttn, tan, len(R)),
+		},
+		probabilities:  probabilities{nA: len(R), nT: n},
+		ttn:            ttn,
+		tan:            tan,
+		absorbingNodes: chain.assignableNodes(),
 	}
 
-	if nodes.GetCardinality() != chain.Nodes.GetCardinality() {
-		v, _ := roaring.AndNot(chain.Nodes, nodes).Select(0)
-		return errors.Errorf("%v isn't transient node, neither it's declared absorbing.", v)
+	artifactsDir := chain.artifactsDir
+	if artifactsDir != "" {
+		if err = writeArtifacts(artifactsDir, results, pr, groups != nil); err != nil {
+			return nil, err
+		}
+		results.Report.ArtifactsDir = artifactsDir
 	}
 
-	chain.Weighter = checkedWeighter(chain.Weighter)
-
-	return
-}
+	//enable eventual GC
+	chain = nil
+	clean()
+	debug.FreeOSMemory()
 
-func (chain *AbsorbingMarkovChain) checkGraphNodes() (err error) {
-	nodes := chain.Nodes
-	for i := nodes.Iterator(); i.HasNext(); {
-		from := i.Next()
-		to := chain.Edges(from)
-		for _, id := range to {
-			if !nodes.Contains(id) {
-				return errors.Errorf("arc (%v,%v) shouldn't exist: %v isn't a graph node.", from, id, id)
-			}
+	//run solver
+	for lo := 0; lo < len(R); lo += batch {
+		hi := lo + batch
+		if hi > len(R) {
+			hi = len(R)
+		}
+		B := denseRHS(R[lo:hi], n)
+		if artifactsDir != "" {
+			system.workDir = artifactsBatchDir(artifactsDir, lo)
+		}
+		if err = results.solveBatch(ctx, system, B, lo, pr, groups != nil); err != nil {
+			return nil, err
 		}
 	}
+
 	return
 }
 
-func (chain *AbsorbingMarkovChain) checkAbsorbingNodes(nodes *roaring.Bitmap) (err error) {
-	for i := chain.absorbingNodes.Iterator(); i.HasNext(); {
-		ANode := i.Next()
-		to := chain.Edges(ANode)
-		switch {
-		case len(to) > 1:
-			fallthrough
-		case len(to) == 1 && to[0] != ANode:
-			return errors.Errorf("%v is not a valid absorbing node.", ANode)
-		default:
-			nodes.Add(ANode)
+// solveBatch solves the right hand sides in B, the ones of the absorbing nodes or groups from offset on,
+// storing their solutions and stats in the results.
+func (r *Results) solveBatch(ctx context.Context, system linearSystem, B [][]float64, offset int, pr pruning, groups bool) (err error) {
+	X, stats, err := system.solve(ctx, B, func(i int) *DivergenceError {
+		node, _ := r.tan.ToOld(uint32(offset + i))
+		target := fmt.Sprint("absorption probabilities into ", node)
+		if groups {
+			target = fmt.Sprint("absorption probabilities into group ", node)
 		}
+		return &DivergenceError{Target: target, Node: node}
+	})
+	if err != nil {
+		return
 	}
-	return
-}
 
-func (chain *AbsorbingMarkovChain) checkTransientNodes(nodes *roaring.Bitmap) (err error) {
-	changed := true
-	for changed {
-		changed = false
-		for i := roaring.AndNot(chain.Nodes, chain.absorbingNodes).Iterator(); i.HasNext(); {
-			from := i.Next()
-			if !nodes.Contains(from) {
-				to := chain.Edges(from)
-				for _, id := range to {
-					if nodes.Contains(id) {
-						nodes.Add(from)
-						changed = true
-						break
-					}
-				}
-			}
+	for i, s := range stats {
+		oldID, err := r.tan.ToOld(uint32(offset + i))
+		if err != nil {
+			return err
 		}
+		r.Report.Stats[oldID] = s
 	}
-	return
-}
 
-func checkedWeighter(weighter func(from, to uint32) (weight float64, err error)) func(from, to uint32) (weight float64, err error) {
-	return func(from, to uint32) (weight float64, err error) {
-		weight, err = weighter(from, to)
-		switch {
-		case err != nil:
-			//err already set
-		case weight <= 0:
-			err = errors.Errorf("arc (%v,%v) hasn't positive weight (%v).", from, to, weight)
-		case math.IsInf(weight, 0):
-			err = errors.Errorf("arc (%v,%v) has infinite weight (%v).", from, to, weight)
-		case math.IsNaN(weight):
-			err = errors.Errorf("arc (%v,%v) has NaN weight (%v).", from, to, weight)
-		}
-		return
+	switch {
+	case pr.enabled():
+		r.Report.DroppedMass += r.probabilities.prune(X, offset, pr)
+	default:
+		r.probabilities.dense = append(r.probabilities.dense, X...)
 	}
+	return
 }
@@ -1,19 +1,589 @@
 package absorbingmarkovchain
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/RoaringBitmap/roaring"
 )
 
 func TestAbsorptionProbabilities(t *testing.T) {
 	chain, tn2anw := amcSample()
-	weighter, err := chain.AbsorptionProbabilities(context.Background())
+	testAbsorptionProbabilities(t, chain, tn2anw, 1.e-15)
+}
+
+func TestAbsorptionAssignments(t *testing.T) {
+	chain, tn2anw := amcSample()
+	testAbsorptionAssignments(t, chain, tn2anw)
+}
+
+func TestTieBreaking(t *testing.T) {
+	//1 and 4 have tied absorbing nodes 0 and 2, while 3 is absorbed by 2
+	m := map[uint32][]uint32{1: {0, 2}, 3: {2}, 4: {0, 2}}
+	tiedChain := func(options ...Option) *AbsorbingMarkovChain {
+		return New("", roaring.BitmapOf(0, 1, 2, 3, 4), roaring.BitmapOf(0, 2), func(from uint32) []uint32 { return m[from] }, func(from, to uint32) (float64, error) { return 1, nil }, append(options, WithSolver(DenseSolver{}))...)
+	}
+
+	for p, expected := range map[TieBreaking]map[uint32]uint32{TieLowestID: {1: 0, 3: 2, 4: 0}, TieHighestID: {1: 2, 3: 2, 4: 2}, TieAmbiguous: {3: 2}} {
+		assigner, err := tiedChain(WithTieBreaking(p)).AbsorptionAssignments(context.Background())
+		if e, ok := err.(*AmbiguityError); p == TieAmbiguous && (!ok || !e.Nodes.Equals(roaring.BitmapOf(1, 4))) {
+			t.Errorf("1 and 4 should be reported as ambiguous, while the error is %v", err)
+		} else if p != TieAmbiguous && err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(assigner, expected) {
+			t.Errorf("Policy %v should produce %v, while produces %v", p, expected, assigner)
+		}
+	}
+
+	first, err := tiedChain(WithRand(rand.New(rand.NewSource(42)))).AbsorptionAssignments(context.Background())
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		assigner, err := tiedChain(WithRand(rand.New(rand.NewSource(42)))).AbsorptionAssignments(context.Background())
+		switch {
+		case err != nil:
+			t.Fatal(err)
+		case !reflect.DeepEqual(assigner, first):
+			t.Errorf("Seeded assignments should be reproducible, while %v differs from %v", assigner, first)
+		}
+	}
+}
+
+func TestAbsorptionTopK(t *testing.T) {
+	for _, k := range []int{1, 2, 3} {
+		chain, tn2anw := amcSample()
+		topK, err := chain.AbsorptionTopK(context.Background(), k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for tn, nodes := range tn2anw {
+			top, e := topK[tn], k
+			if len(nodes) < e {
+				e = len(nodes)
+			}
+			if len(top) != e {
+				t.Errorf("%v should have %v absorbing nodes, but it has %v", tn, e, len(top))
+				continue
+			}
+			for i, nw := range top {
+				if nw.Node != nodes[i].to || math.Abs(nw.Weight-nodes[i].w) > 1.e-15 {
+					t.Errorf("%v should have %v as absorbing node of rank %v, but it has %v", tn, nodes[i], i, nw)
+				}
+			}
+		}
+	}
+}
+
+func TestAbsorbedBy(t *testing.T) {
+	chain, tn2anw := amcSample()
+	for _, a := range []uint32{0, 1} {
+		expected := []NodeWeight{}
+		for tn, nodes := range tn2anw {
+			for _, node := range nodes {
+				if node.to == a {
+					expected = append(expected, NodeWeight{tn, node.w})
+				}
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool { return expected[i].Weight > expected[j].Weight })
+
+		for _, k := range []int{1, 2, 100} {
+			topK, err := chain.AbsorbedBy(context.Background(), a, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l := len(expected); k > l {
+				k = l
+			}
+			if len(topK) != k {
+				t.Fatalf("Expected %v nodes, found %v", k, len(topK))
+			}
+			for i, nw := range topK {
+				if math.Abs(nw.Weight-expected[i].Weight) > 1e-15 || (i > 0 && topK[i-1].Weight < nw.Weight) {
+					t.Errorf("Unexpected top-%v into %v: %v", k, a, topK)
+					break
+				}
+			}
+		}
+	}
+
+	if _, err := chain.AbsorbedBy(context.Background(), 2, 1); err == nil {
+		t.Error("2 is not an absorbing node.")
+	}
+}
+
+func TestWithTargets(t *testing.T) {
+	chain, tn2anw := amcSample(WithTargets(roaring.BitmapOf(1)))
+	weighter, report, err := chain.AbsorptionProbabilities(context.Background())
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(report.Stats) != 1:
+		t.Errorf("Expected a single solve, found %v", len(report.Stats))
+	}
+	for tn, nodes := range tn2anw {
+		for _, node := range nodes {
+			w, err := weighter(tn, node.to)
+			switch {
+			case node.to != 1 && err == nil:
+				t.Errorf("Absorption probabilities into %v should not be computed", node.to)
+			case node.to == 1 && err != nil:
+				t.Error(err)
+			case node.to == 1 && math.Abs(w-node.w) > 1e-15:
+				t.Errorf("The assignment probability in edge (%v,%v) is %v while is expected %v", tn, node.to, w, node.w)
+			}
+		}
+	}
+}
+
+func TestGroupAbsorptionProbabilities(t *testing.T) {
+	chain, tn2anw := amcSample()
+	weighter, report, err := chain.GroupAbsorptionProbabilities(context.Background(), map[uint32]uint32{0: 42, 1: 42})
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(report.Stats) != 1:
+		t.Errorf("Expected a single solve, found %v", len(report.Stats))
+	}
+	for tn := range tn2anw {
+		if w, err := weighter(tn, 42); err != nil || math.Abs(w-1) > 1e-15 {
+			t.Errorf("The probability of absorption of %v into the only group is %v, error %v", tn, w, err)
+		}
+	}
+
+	weighter, _, err = chain.GroupAbsorptionProbabilities(context.Background(), map[uint32]uint32{1: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tn, nodes := range tn2anw {
+		for _, node := range nodes {
+			if node.to != 1 {
+				continue
+			}
+			if w, err := weighter(tn, 7); err != nil || math.Abs(w-node.w) > 1e-15 {
+				t.Errorf("The probability of absorption of %v into group 7 is %v while is expected %v, error %v", tn, w, node.w, err)
+			}
+		}
+	}
+
+	if _, _, err := chain.GroupAbsorptionProbabilities(context.Background(), map[uint32]uint32{2: 7}); err == nil {
+		t.Error("2 is not an absorbing node.")
+	}
+}
+
+func TestWithPruning(t *testing.T) {
+	for _, pr := range []struct {
+		epsilon float64
+		k       int
+	}{{0.5, 0}, {0, 1}, {0.65, 1}} {
+		chain, tn2anw := amcSample(WithPruning(pr.epsilon, pr.k))
+		weighter, report, err := chain.AbsorptionProbabilities(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		dropped := 0.0
+		for tn, nodes := range tn2anw {
+			for i, node := range nodes { //edges are sorted by descending weight
+				expected := node.w
+				if node.w <= pr.epsilon || (pr.k > 0 && i >= pr.k) {
+					dropped += expected
+					expected = 0
+				}
+				if w, err := weighter(tn, node.to); err != nil || math.Abs(w-expected) > 1e-15 {
+					t.Errorf("With pruning %v, the probability in edge (%v,%v) is %v while is expected %v, error %v", pr, tn, node.to, w, expected, err)
+				}
+			}
+		}
+		if math.Abs(report.DroppedMass-dropped) > 1e-14 {
+			t.Errorf("With pruning %v, the dropped mass is %v while is expected %v", pr, report.DroppedMass, dropped)
+		}
+	}
+}
+
+func TestResults(t *testing.T) {
+	chain, tn2anw := amcSample()
+	results, err := chain.AbsorptionResults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pairs [][2]uint32
+	err = results.Each(func(transient, absorbing uint32, p float64) bool {
+		pairs = append(pairs, [2]uint32{transient, absorbing})
+		if w, err := results.Weight(transient, absorbing); err != nil || w != p {
+			t.Errorf("Each reports %v for edge (%v,%v) while Weight reports %v, error %v", p, transient, absorbing, w, err)
+		}
+		return true
+	})
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(pairs) != 2*len(tn2anw):
+		t.Errorf("Expected %v pairs, found %v", 2*len(tn2anw), len(pairs))
+	case !sort.SliceIsSorted(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0] || (pairs[i][0] == pairs[j][0] && pairs[i][1] < pairs[j][1])
+	}):
+		t.Errorf("Pairs are not in original ID order: %v", pairs)
+	}
+
+	count := 0
+	results.Each(func(transient, absorbing uint32, p float64) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("Each should stop when f returns false, it called f %v times", count)
+	}
+
+	for tn, nodes := range tn2anw {
+		row, err := results.Transient(tn)
+		if err != nil || len(row) != len(nodes) {
+			t.Errorf("Unexpected probabilities of %v: %v, error %v", tn, row, err)
+		}
+		for _, nw := range row {
+			for _, node := range nodes {
+				if node.to == nw.Node && math.Abs(node.w-nw.Weight) > 1e-15 {
+					t.Errorf("The assignment probability in edge (%v,%v) is %v while is expected %v", tn, node.to, nw.Weight, node.w)
+				}
+			}
+		}
+	}
+	if column, err := results.Absorbing(0); err != nil || len(column) != len(tn2anw) {
+		t.Errorf("Unexpected probabilities of absorption into 0: %v, error %v", column, err)
+	}
+	if _, err := results.Absorbing(2); err == nil {
+		t.Error("2 is not an absorbing node.")
+	}
+
+	chain, _ = amcSample(WithTargets(roaring.BitmapOf(1)))
+	if results, err = chain.AbsorptionResults(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, e1 := results.Weight(2, 0)
+	_, e2 := results.Absorbing(0)
+	if e1 == nil || e2 == nil || e1.Error() != e2.Error() {
+		t.Errorf("Weight and Absorbing should report that 0 is not a target, errors %v and %v", e1, e2)
+	}
+}
+
+func TestEmptyResults(t *testing.T) {
+	results := &Results{probabilities: probabilities{nT: 2}, ttn: myTranslator{2, 3}, tan: myTranslator{}, absorbingNodes: roaring.BitmapOf(0)}
+	count := 0
+	if err := results.Each(func(transient, absorbing uint32, p float64) bool { count++; return true }); err != nil || count != 0 {
+		t.Errorf("Each on results without solved absorbing nodes called f %v times, error %v", count, err)
+	}
+	if row, err := results.Transient(2); err != nil || len(row) != 0 {
+		t.Errorf("Unexpected probabilities of 2: %v, error %v", row, err)
+	}
+	if assigner, err := results.Assignments(TieLowestID, nil); err != nil || len(assigner) != 0 {
+		t.Errorf("Unexpected assignments: %v, error %v", assigner, err)
+	}
+	if topK, err := results.TopK(1); err != nil || len(topK[2]) != 0 {
+		t.Errorf("Unexpected top-k: %v, error %v", topK, err)
+	}
+}
+
+func TestReadResults(t *testing.T) {
+	for _, options := range [][]Option{nil, {WithPruning(0.5, 0)}} {
+		chain, _ := amcSample(options...)
+		results, err := chain.AbsorptionResults(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		n, err := results.WriteTo(&buf)
+		switch {
+		case err != nil:
+			t.Fatal(err)
+		case n != int64(buf.Len()):
+			t.Errorf("WriteTo reports %v bytes while %v were written", n, buf.Len())
+		}
+		loaded, err := ReadResults(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		type entry struct {
+			transient, absorbing uint32
+			p                    float64
+		}
+		entries := func(r *Results) (ee []entry) {
+			r.Each(func(transient, absorbing uint32, p float64) bool {
+				ee = append(ee, entry{transient, absorbing, p})
+				return true
+			})
+			return
+		}
+		switch {
+		case !reflect.DeepEqual(entries(results), entries(loaded)):
+			t.Errorf("Loaded probabilities %v differ from saved ones %v", entries(loaded), entries(results))
+		case !reflect.DeepEqual(results.Metadata, loaded.Metadata):
+			t.Errorf("Loaded metadata %v differ from saved ones %v", loaded.Metadata, results.Metadata)
+		case !reflect.DeepEqual(results.Report.Stats, loaded.Report.Stats) || results.Report.DroppedMass != loaded.Report.DroppedMass:
+			t.Errorf("Loaded report %v differ from saved one %v", loaded.Report, results.Report)
+		}
+		a1, err1 := results.Assignments(TieLowestID, nil)
+		a2, err2 := loaded.Assignments(TieLowestID, nil)
+		if err1 != nil || err2 != nil || !reflect.DeepEqual(a1, a2) {
+			t.Errorf("Loaded assignments %v differ from saved ones %v, errors %v %v", a2, a1, err1, err2)
+		}
+	}
+
+	if _, err := ReadResults(bytes.NewReader([]byte("not results"))); err == nil {
+		t.Error("Reading a file with a wrong format should fail.")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	for _, options := range [][]Option{nil, {WithPruning(0.5, 0)}, {WithSolver(BlockSolver{Solver: GMRESSolver{}, MinBlockSize: 1})}} {
+		dir, err := ioutil.TempDir("", "artifacts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		chain, _ := amcSample(append(options, WithArtifactsDir(dir))...)
+		results, err := chain.AbsorptionResults(context.Background())
+		switch {
+		case err != nil:
+			t.Fatal(err)
+		case results.Report.ArtifactsDir != dir:
+			t.Errorf("Artifacts kept in %v, while %v is reported", dir, results.Report.ArtifactsDir)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "rhs-000000000", "Ab.ptsc")); err != nil {
+			t.Error(err)
+		}
+
+		replayed, err := Replay(context.Background(), dir, DenseSolver{}, SolverOptions{})
+		switch {
+		case err != nil:
+			t.Fatal(err)
+		case replayed.Metadata.SystemHash != results.Metadata.SystemHash:
+			t.Errorf("The replayed system hash is %v while is expected %v", replayed.Metadata.SystemHash, results.Metadata.SystemHash)
+		case math.Abs(replayed.Report.DroppedMass-results.Report.DroppedMass) > 1e-14:
+			t.Errorf("The replayed dropped mass is %v while is expected %v", replayed.Report.DroppedMass, results.Report.DroppedMass)
+		}
+		results.Each(func(transient, absorbing uint32, p float64) bool {
+			if w, err := replayed.Weight(transient, absorbing); err != nil || math.Abs(w-p) > 1e-14 {
+				t.Errorf("The replayed probability in edge (%v,%v) is %v while is expected %v, error %v", transient, absorbing, w, p, err)
+			}
+			return true
+		})
+	}
+
+	if _, err := Replay(context.Background(), os.TempDir(), nil, SolverOptions{}); err == nil {
+		t.Error("Replay of a directory without artifacts should fail.")
+	}
+
+	dir, err := ioutil.TempDir("", "artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, targets := range []*roaring.Bitmap{nil, roaring.BitmapOf(1)} { //same directory, fewer targets
+		chain, _ := amcSample(WithArtifactsDir(dir), WithTargets(targets), WithPruning(0.5, 0))
+		chain.pruning.batch = 1
+		if _, err := chain.AbsorptionResults(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Replay(context.Background(), dir, DenseSolver{}, SolverOptions{}); err != nil {
+		t.Errorf("Replay of a reused directory failed: %v", err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(artifactsBatchDir(dir, 0), artifactsSystem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir(artifactsBatchDir(dir, 1), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(artifactsBatchDir(dir, 1), artifactsSystem), b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Replay(context.Background(), dir, DenseSolver{}, SolverOptions{}); err == nil {
+		t.Error("Replay of a directory with more batches than right hand sides should fail.")
+	}
+
+	chain, _ := amcSample(WithArtifactsDir(os.TempDir()))
+	if _, err := chain.ExpectedStepsToAbsorption(context.Background()); err == nil {
+		t.Error("WithArtifactsDir should be rejected by ExpectedStepsToAbsorption.")
+	}
+	if _, err := chain.AbsorptionTimeVariance(context.Background()); err == nil {
+		t.Error("WithArtifactsDir should be rejected by AbsorptionTimeVariance.")
+	}
+	if _, err := chain.ExpectedVisits(context.Background(), roaring.BitmapOf(2), 0); err == nil {
+		t.Error("WithArtifactsDir should be rejected by ExpectedVisits.")
+	}
+}
+
+func TestWithEmptyTargets(t *testing.T) {
+	chain, _ := amcSample(WithTargets(roaring.NewBitmap()))
+	if _, err := chain.AbsorptionAssignments(context.Background()); err == nil {
+		t.Error("An empty set of targets should be rejected.")
+	}
+	if _, err := chain.AbsorptionResults(context.Background()); err == nil {
+		t.Error("An empty set of targets should be rejected.")
+	}
+}
+
+func TestBatches(t *testing.T) {
+	chain, tn2anw := amcSample(WithPruning(0, 2)) //retains all the entries
+	chain.pruning.batch = 1
+	testAbsorptionProbabilities(t, chain, tn2anw, 1.e-15)
+	testAbsorptionAssignments(t, chain, tn2anw)
+
+	dir, err := ioutil.TempDir("", "artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	chain, _ = amcSample(WithArtifactsDir(dir), WithPruning(0.5, 0))
+	chain.pruning.batch = 1
+	results, err := chain.AbsorptionResults(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := Replay(context.Background(), dir, DenseSolver{}, SolverOptions{})
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case replayed.Metadata.SystemHash != results.Metadata.SystemHash:
+		t.Errorf("The replayed system hash is %v while is expected %v", replayed.Metadata.SystemHash, results.Metadata.SystemHash)
+	}
+	if batches, _ := filepath.Glob(filepath.Join(dir, artifactsBatchPrefix+"*")); len(batches) != 2 {
+		t.Errorf("Expected a batch for each absorbing node, found %v", batches)
+	}
+}
+
+func TestBlockSolver(t *testing.T) {
+	for _, minBlockSize := range []int{0, 1, 2} {
+		chain, tn2anw := amcSample(WithSolver(BlockSolver{Solver: GMRESSolver{}, MinBlockSize: minBlockSize}))
+		testAbsorptionProbabilities(t, chain, tn2anw, 1.e-15)
+	}
+}
+
+func TestEstimateAbsorptionProbabilities(t *testing.T) {
+	chain, tn2anw := amcSample(WithRand(rand.New(rand.NewSource(42))))
+	estimator, err := chain.EstimateAbsorptionProbabilities(context.Background(), MonteCarloOptions{Walks: 10000, Confidence: 0.999, Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tn, nodes := range tn2anw {
+		for _, node := range nodes {
+			e, err := estimator(tn, node.to)
+			switch {
+			case err != nil:
+				t.Error(err)
+			case node.w < e.Low || node.w > e.High || e.P < e.Low || e.P > e.High:
+				t.Errorf("The assignment probability in edge (%v,%v) is %v while is estimated as %v", tn, node.to, node.w, e)
+			}
+		}
+	}
+
+	//the default number of workers gives the same estimates as a single one
+	serial, _ := amcSample(WithRand(rand.New(rand.NewSource(7))))
+	parallel, _ := amcSample(WithRand(rand.New(rand.NewSource(7))))
+	se, err := serial.EstimateAbsorptionProbabilities(context.Background(), MonteCarloOptions{Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pe, err := parallel.EstimateAbsorptionProbabilities(context.Background(), MonteCarloOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tn, nodes := range tn2anw {
+		for _, node := range nodes {
+			s, _ := se(tn, node.to)
+			p, _ := pe(tn, node.to)
+			if s != p {
+				t.Errorf("The estimates in edge (%v,%v) are %v with a single worker and %v by default", tn, node.to, s, p)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := chain.EstimateAbsorptionProbabilities(ctx, MonteCarloOptions{}); err == nil {
+		t.Error("The estimate should fail with a cancelled context.")
+	}
+	if _, err := chain.EstimateAbsorptionProbabilities(context.Background(), MonteCarloOptions{Sources: roaring.BitmapOf(0)}); err == nil {
+		t.Error("0 is an absorbing node, it can't be a source.")
+	}
+
+	//walks between 2 and 3 last about 1e12 steps
+	m := map[uint32][]uint32{2: {0, 3}, 3: {2}}
+	chain = New("", roaring.BitmapOf(0, 2, 3), roaring.BitmapOf(0), func(from uint32) []uint32 { return m[from] }, func(from, to uint32) (float64, error) {
+		if to == 0 {
+			return 1e-12, nil
+		}
+		return 1, nil
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := chain.EstimateAbsorptionProbabilities(ctx, MonteCarloOptions{Walks: 1}); err != context.DeadlineExceeded {
+		t.Errorf("A long walk should stop when the context is done, error %v", err)
+	}
+}
+
+func TestLocalAbsorptionProbabilities(t *testing.T) {
+	chain, tn2anw := amcSample()
+	for tn, nodes := range tn2anw {
+		probabilities, residual, err := chain.LocalAbsorptionProbabilities(context.Background(), tn, 1e-12)
+		switch {
+		case err != nil:
+			t.Fatal(err)
+		case residual > 1e-12:
+			t.Errorf("Residual %v exceeds the required epsilon", residual)
+		}
+		for _, node := range nodes {
+			if p := probabilities[node.to]; math.Abs(p-node.w) > 1e-11 {
+				t.Errorf("The assignment probability in edge (%v,%v) is %v while is expected %v", tn, node.to, p, node.w)
+			}
+		}
+	}
+
+	if _, _, err := chain.LocalAbsorptionProbabilities(context.Background(), 42, 1e-12); err == nil {
+		t.Error("42 is not a node of the chain.")
+	}
+	for _, epsilon := range []float64{0, -1, math.NaN()} {
+		if _, _, err := chain.LocalAbsorptionProbabilities(context.Background(), 4, epsilon); err == nil {
+			t.Errorf("%v is not a valid epsilon.", epsilon)
+		}
+	}
+}
+
+func TestDenseSolver(t *testing.T) {
+	chain, tn2anw := amcSample(WithSolver(DenseSolver{}))
+	testAbsorptionProbabilities(t, chain, tn2anw, 1.e-15)
+	testAbsorptionAssignments(t, chain, tn2anw)
+}
+
+func testAbsorptionProbabilities(t *testing.T, chain *AbsorbingMarkovChain, tn2anw map[uint32][]implicitWeightedEdge, eps float64) {
+	solves := int(chain.absorbingNodes.GetCardinality())
+	weighter, report, err := chain.AbsorptionProbabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for an, s := range report.Stats {
+		if !s.Reason.Converged() {
+			t.Errorf("The solve for %v should converge, but it ends with reason %v", an, s.Reason)
+		}
+	}
+	if len(report.Stats) != solves {
+		t.Errorf("The report should contain %v solves, but it contains %v", solves, len(report.Stats))
 	}
-	const eps = 1.e-15
 	for tn, nodes := range tn2anw {
 		for _, node := range nodes {
 			w, err := weighter(tn, node.to)
@@ -26,11 +596,11 @@ func TestAbsorptionProbabilities(t *testing.T) {
 		}
 	}
 }
-func TestAbsorptionAssignments(t *testing.T) {
-	chain, tn2anw := amcSample()
+
+func testAbsorptionAssignments(t *testing.T, chain *AbsorbingMarkovChain, tn2anw map[uint32][]implicitWeightedEdge) {
 	assigner, err := chain.AbsorptionAssignments(context.Background())
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 	for tn, nodes := range tn2anw {
 		an, ok := assigner[tn]
@@ -43,7 +613,270 @@ func TestAbsorptionAssignments(t *testing.T) {
 	}
 }
 
-func amcSample() (chain *AbsorbingMarkovChain, tn2anw map[uint32][]implicitWeightedEdge) {
+type countingSolver struct {
+	Solver
+	calls int
+}
+
+func (s *countingSolver) Solve(ctx context.Context, A *SparseMatrix, B [][]float64, o SolverOptions) ([][]float64, []SolveStats, error) {
+	s.calls++
+	return s.Solver.Solve(ctx, A, B, o)
+}
+
+func TestWithSolver(t *testing.T) {
+	solver := &countingSolver{Solver: GMRESSolver{}}
+	chain, _ := amcSample(WithSolver(solver))
+	if _, _, err := chain.AbsorptionProbabilities(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if solver.calls != 1 {
+		t.Errorf("The solver has been called %v times, instead of once.", solver.calls)
+	}
+}
+
+func TestWithSolverOptions(t *testing.T) {
+	for _, pc := range []string{"sor", "jacobi", "none"} {
+		chain, tn2anw := amcSample(WithSolver(GMRESSolver{}), WithSolverOptions(SolverOptions{RTol: 1e-12, Restart: 2, PCType: pc}))
+		testAbsorptionProbabilities(t, chain, tn2anw, 1.e-10)
+	}
+
+	o := SolverOptions{ATol: ZeroTol, DTol: 2e4}.withDefaults()
+	if o.RTol != DefaultSolverOptions.RTol || o.ATol != 0 || o.DTol != 2e4 {
+		t.Errorf("Unexpected tolerances %v, %v and %v", o.RTol, o.ATol, o.DTol)
+	}
+	chain, tn2anw := amcSample(WithSolver(GMRESSolver{}), WithSolverOptions(SolverOptions{RTol: 1e-12, ATol: ZeroTol}))
+	testAbsorptionProbabilities(t, chain, tn2anw, 1.e-10)
+
+	chain, _ = amcSample(WithSolver(GMRESSolver{}), WithSolverOptions(SolverOptions{KSPType: "bcgs"}))
+	if _, _, err := chain.AbsorptionProbabilities(context.Background()); err == nil {
+		t.Error("GMRESSolver should fail with an unsupported Krylov method.")
+	}
+}
+
+func TestDivergenceError(t *testing.T) {
+	chain, _ := amcSample(WithSolver(GMRESSolver{}), WithSolverOptions(SolverOptions{RTol: 1e-12, MaxIterations: 1, Restart: 1, PCType: "none"}))
+	_, _, err := chain.AbsorptionProbabilities(context.Background())
+	if e, ok := err.(*DivergenceError); !ok || e.Reason != DivergedIts {
+		t.Errorf("The solve should diverge for reaching the maximum number of iterations, while it ends with error %v", err)
+	}
+}
+
+func TestExpectedStepsToAbsorption(t *testing.T) {
+	expected := map[uint32]float64{2: 1.8, 3: 1.8, 4: 1.6, 5: 2.8, 6: 2.7, 7: 1 + 3.4/3}
+	for _, solver := range []Solver{GMRESSolver{}, DenseSolver{}} {
+		chain, _ := amcSample(WithSolver(solver))
+		steps, err := chain.ExpectedStepsToAbsorption(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for tn, e := range expected {
+			s, err := steps(tn)
+			switch {
+			case err != nil:
+				t.Error(err)
+			case math.Abs(s-e) > 1.e-12:
+				t.Errorf("The expected steps to absorption of %v are %v while are evaluated as %v", tn, e, s)
+			}
+		}
+		if _, err := steps(0); err == nil {
+			t.Error("0 is an absorbing node, it shouldn't have expected steps to absorption.")
+		}
+	}
+}
+
+func TestAbsorptionTimeVariance(t *testing.T) {
+	//0 absorbing, 1 leaves with probability 1/2 at each step and 2 always moves to 1
+	m := map[uint32][]uint32{1: {0, 1}, 2: {1}}
+	expected := map[uint32]float64{1: 2, 2: 2}
+	for _, solver := range []Solver{GMRESSolver{}, DenseSolver{}} {
+		chain := New("", roaring.BitmapOf(0, 1, 2), roaring.BitmapOf(0), func(from uint32) []uint32 { return m[from] }, func(from, to uint32) (float64, error) { return 1, nil }, WithSolver(solver))
+		variance, err := chain.AbsorptionTimeVariance(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for tn, e := range expected {
+			v, err := variance(tn)
+			switch {
+			case err != nil:
+				t.Error(err)
+			case math.Abs(v-e) > 1.e-12:
+				t.Errorf("The absorption time variance of %v is %v while is evaluated as %v", tn, e, v)
+			}
+		}
+	}
+}
+
+func TestExpectedVisits(t *testing.T) {
+	m := map[uint32][]uint32{1: {0, 1}, 2: {1}}
+	expected := map[uint32][]NodeWeight{1: {{1, 2}}, 2: {{1, 2}, {2, 1}}}
+	chain := New("", roaring.BitmapOf(0, 1, 2), roaring.BitmapOf(0), func(from uint32) []uint32 { return m[from] }, func(from, to uint32) (float64, error) { return 1, nil }, WithSolver(DenseSolver{}))
+	visits, err := chain.ExpectedVisits(context.Background(), roaring.BitmapOf(1, 2), 1.e-12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tn, e := range expected {
+		v := visits[tn]
+		if len(v) != len(e) {
+			t.Errorf("The expected visits from %v are %v while are evaluated as %v", tn, e, v)
+			continue
+		}
+		for i := range e {
+			if v[i].Node != e[i].Node || math.Abs(v[i].Weight-e[i].Weight) > 1.e-12 {
+				t.Errorf("The expected visits from %v are %v while are evaluated as %v", tn, e, v)
+			}
+		}
+	}
+
+	//visits sum up to the expected steps to absorption
+	chain, _ = amcSample(WithSolver(DenseSolver{}))
+	steps, err := chain.ExpectedStepsToAbsorption(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := roaring.BitmapOf(2, 3, 4, 5, 6, 7)
+	if visits, err = chain.ExpectedVisits(context.Background(), sources, 0); err != nil {
+		t.Fatal(err)
+	}
+	for tn, v := range visits {
+		sum := 0.0
+		for _, nw := range v {
+			sum += nw.Weight
+		}
+		if s, _ := steps(tn); math.Abs(s-sum) > 1.e-12 {
+			t.Errorf("The expected visits from %v sum up to %v, instead of %v", tn, sum, s)
+		}
+	}
+	if _, err = chain.ExpectedVisits(context.Background(), roaring.BitmapOf(0), 0); err == nil {
+		t.Error("0 is an absorbing node, it shouldn't have expected visits.")
+	}
+	if _, err = chain.ExpectedVisits(context.Background(), roaring.NewBitmap(), 0); err == nil {
+		t.Error("An empty set of sources should be rejected.")
+	}
+	all, err := chain.ExpectedVisits(context.Background(), nil, 0)
+	if err != nil || !reflect.DeepEqual(all, visits) {
+		t.Errorf("Without sources, the expected visits are %v while are expected %v, error %v", all, visits, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	chain, _ := amcSample()
+	if report, err := chain.Validate(context.Background()); err != nil || len(report.Problems) > 0 {
+		t.Errorf("The sample chain should be valid, while validation reports %v, %v", report.Problems, err)
+	}
+
+	//9 is not a node, 1 is an invalid absorbing node, 3 can't reach absorbing nodes and (2,0) has negative weight
+	m := map[uint32][]uint32{1: {2}, 2: {0, 9}, 3: {3}}
+	chain = New("", roaring.BitmapOf(0, 1, 2, 3), roaring.BitmapOf(0, 1), func(from uint32) []uint32 { return m[from] }, func(from, to uint32) (float64, error) {
+		if from == 2 && to == 0 {
+			return -1, nil
+		}
+		return 1, nil
+	})
+	report, err := chain.Validate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Problem{{Kind: InvalidAbsorbingNode, From: 1}, {Kind: BadWeight, From: 2, To: 0, Weight: -1}, {Kind: DanglingArc, From: 2, To: 9}, {Kind: UnreachableNode, From: 3}}
+	sort.SliceStable(report.Problems, func(i, j int) bool { return report.Problems[i].From < report.Problems[j].From })
+	if !reflect.DeepEqual(report.Problems, expected) {
+		t.Errorf("Validation should report %v, while it reports %v", expected, report.Problems)
+	}
+	if _, _, err := chain.AbsorptionProbabilities(context.Background()); err == nil {
+		t.Error("Absorption probabilities of an invalid chain shouldn't be calculated.")
+	}
+}
+
+func TestNewInferred(t *testing.T) {
+	sample, tn2anw := amcSample()
+	chain := NewInferred("", sample.Nodes, sample.Edges, sample.Weighter)
+	if !chain.absorbingNodes.Equals(sample.absorbingNodes) {
+		t.Errorf("Absorbing nodes should be %v, while are inferred as %v", sample.absorbingNodes, chain.absorbingNodes)
+	}
+	testAbsorptionProbabilities(t, chain, tn2anw, 1.e-15)
+
+	//{2,3} is a closed class
+	m := map[uint32][]uint32{1: {2, 3, 4}, 2: {3}, 3: {2, 3}, 4: {0}}
+	nodes := roaring.BitmapOf(0, 1, 2, 3, 4)
+	edges := func(from uint32) []uint32 { return m[from] }
+	weighter := func(from, to uint32) (float64, error) { return 1, nil }
+	if _, _, err := NewInferred("", nodes, edges, weighter).AbsorptionProbabilities(context.Background()); err == nil {
+		t.Error("Without closed classes, 2 and 3 can't reach any absorbing node.")
+	}
+	chain = NewInferred("", nodes, edges, weighter, WithClosedClasses(true))
+	if e := roaring.BitmapOf(0, 2, 3); !chain.absorbingNodes.Equals(e) {
+		t.Errorf("Absorbing nodes should be %v, while are inferred as %v", e, chain.absorbingNodes)
+	}
+	testAbsorptionProbabilities(t, chain, map[uint32][]implicitWeightedEdge{1: {{0, 1. / 3}, {2, 1. / 3}, {3, 1. / 3}}, 4: {{0, 1}}}, 1.e-15)
+}
+
+func TestUnreachablePolicy(t *testing.T) {
+	//{2,3} can't reach 0, 4 reaches 0 through 1
+	m := map[uint32][]uint32{1: {0}, 2: {3}, 3: {2}, 4: {1, 2}}
+	islandChain := func(options ...Option) *AbsorbingMarkovChain {
+		return New("", roaring.BitmapOf(0, 1, 2, 3, 4), roaring.BitmapOf(0), func(from uint32) []uint32 { return m[from] }, func(from, to uint32) (float64, error) { return 1, nil }, append(options, WithSolver(DenseSolver{}))...)
+	}
+
+	if _, _, err := islandChain().AbsorptionProbabilities(context.Background()); err == nil {
+		t.Error("By default, unreachable nodes should make the computation fail.")
+	}
+
+	for p, tn2anw := range map[UnreachablePolicy]map[uint32][]implicitWeightedEdge{
+		DropUnreachable:   {1: {{0, 1}}, 4: {{0, 1}}},
+		AbsorbUnreachable: {1: {{0, 1}}, 4: {{0, 0.5}}},
+	} {
+		weighter, report, err := islandChain(WithUnreachablePolicy(p)).AbsorptionProbabilities(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.Unreachable.Equals(roaring.BitmapOf(2, 3)) {
+			t.Errorf("Policy %v should report 2 and 3 as unreachable, while reports %v", p, report.Unreachable)
+		}
+		for tn, nodes := range tn2anw {
+			for _, node := range nodes {
+				if w, err := weighter(tn, node.to); err != nil || math.Abs(w-node.w) > 1.e-15 {
+					t.Errorf("Policy %v: the assignment probability in edge (%v,%v) is %v while is evaluated as %v, %v", p, tn, node.to, node.w, w, err)
+				}
+			}
+		}
+		if _, err := weighter(2, 0); err == nil {
+			t.Errorf("Policy %v: 2 shouldn't have absorption probabilities.", p)
+		}
+		if _, err := weighter(4, 2); err == nil {
+			t.Errorf("Policy %v: 2 shouldn't be a target.", p)
+		}
+
+		assigner, err := islandChain(WithUnreachablePolicy(p)).AbsorptionAssignments(context.Background())
+		if err != nil || !reflect.DeepEqual(assigner, map[uint32]uint32{1: 0, 4: 0}) {
+			t.Errorf("Policy %v: unexpected assignments %v, error %v", p, assigner, err)
+		}
+		if _, err := islandChain(WithUnreachablePolicy(p)).AbsorbedBy(context.Background(), 2, 1); err == nil {
+			t.Errorf("Policy %v: 2 shouldn't be ranked as an absorbing node.", p)
+		}
+	}
+
+	chain := islandChain(WithUnreachablePolicy(AbsorbUnreachable), WithRand(rand.New(rand.NewSource(42))))
+	if _, err := chain.ExpectedStepsToAbsorption(context.Background()); err == nil {
+		t.Error("Expected steps to absorption should fail with unassigned sinks.")
+	}
+	if _, err := chain.AbsorptionTimeVariance(context.Background()); err == nil {
+		t.Error("Absorption time variance should fail with unassigned sinks.")
+	}
+	if _, err := chain.ExpectedVisits(context.Background(), roaring.BitmapOf(4), 0); err == nil {
+		t.Error("Expected visits should fail with unassigned sinks.")
+	}
+	estimator, err := chain.EstimateAbsorptionProbabilities(context.Background(), MonteCarloOptions{Sources: roaring.BitmapOf(4)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, err := estimator(4, 0); err != nil || e.Low > 0.5 || e.High < 0.5 {
+		t.Errorf("The estimate of absorption of 4 into 0 is %v, error %v", e, err)
+	}
+	if _, err := estimator(4, 2); err == nil {
+		t.Error("2 is an unassigned sink, it shouldn't be estimated.")
+	}
+}
+
+func amcSample(options ...Option) (chain *AbsorbingMarkovChain, tn2anw map[uint32][]implicitWeightedEdge) {
 	m := map[uint32][]uint32{2: {0, 4}, 3: {1, 4}, 4: {0, 1, 2}, 5: {3}, 6: {2, 4}, 7: {1, 3, 4}}
 	//edges are sorted by descending weight
 	tn2anw = map[uint32][]implicitWeightedEdge{2: {{0, 0.8}, {1, 0.2}}, 3: {{1, 0.7}, {0, 0.3}}, 4: {{0, 0.6}, {1, 0.4}}, 5: {{1, 0.7}, {0, 0.3}}, 6: {{0, 0.7}, {1, 0.3}}, 7: {{1, 0.7}, {0, 0.3}}}
@@ -66,16 +899,6 @@ func amcSample() (chain *AbsorbingMarkovChain, tn2anw map[uint32][]implicitWeigh
 		}
 	}
 
-	chain = &AbsorbingMarkovChain{
-		wDGraph{
-			dGraph{
-				nodes,
-				func(from uint32) []uint32 { return m[from] },
-			},
-			func(from, to uint32) (weight float64, err error) { return 1, nil },
-		},
-		absorbingNodes,
-		"",
-	}
+	chain = New("", nodes, absorbingNodes, func(from uint32) []uint32 { return m[from] }, func(from, to uint32) (weight float64, err error) { return 1, nil }, options...)
 	return
 }
@@ -0,0 +1,95 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+// ExpectedStepsToAbsorption calculates, for each transient node, the expected number of steps before being absorbed,
+// that is t = N·1 where N = (I-Q)^-1 is the fundamental matrix of the chain.
+func (chain *AbsorbingMarkovChain) ExpectedStepsToAbsorption(ctx context.Context) (steps func(node uint32) (steps float64, err error), err error) {
+	t, system, err := chain.expectedSteps(ctx, func() { chain = nil }) //enable eventual GC
+	if err != nil {
+		return
+	}
+
+	return transientLookup(t, system.ttn), nil
+}
+
+// AbsorptionTimeVariance calculates, for each transient node, the variance of the number of steps before being absorbed,
+// that is (2N-I)·t - t∘t where N = (I-Q)^-1 is the fundamental matrix of the chain and t the expected number of steps.
+func (chain *AbsorbingMarkovChain) AbsorptionTimeVariance(ctx context.Context) (variance func(node uint32) (variance float64, err error), err error) {
+	t, system, err := chain.expectedSteps(ctx, func() { chain = nil }) //enable eventual GC
+	if err != nil {
+		return
+	}
+
+	//Nt = N·t
+	X, _, err := system.solve(ctx, [][]float64{t}, func(int) *DivergenceError {
+		return &DivergenceError{Target: "absorption time variance"}
+	})
+	if err != nil {
+		return
+	}
+	v := X[0]
+	for i, Nt := range v {
+		v[i] = 2*Nt - t[i] - t[i]*t[i]
+	}
+
+	return transientLookup(v, system.ttn), nil
+}
+
+func (chain *AbsorbingMarkovChain) expectedSteps(ctx context.Context, clean func()) (t []float64, system linearSystem, err error) {
+	fail := func(e error) ([]float64, linearSystem, error) {
+		t, system, err = nil, linearSystem{}, e
+		return t, system, err
+	}
+
+	if chain, err = chain.checkRequirements(); err != nil {
+		return fail(err)
+	}
+	if err = chain.checkArtifactsDir(); err != nil {
+		return fail(err)
+	}
+	if err = chain.checkSinks(); err != nil {
+		return fail(err)
+	}
+
+	//transform wikigraph to the linear system
+	A, ttn, _, err := graph2Matrix(chain)
+	if err != nil {
+		return fail(err)
+	}
+	system = chain.linearSystem(A, ttn)
+
+	//enable eventual GC
+	chain = nil
+	clean()
+	debug.FreeOSMemory()
+
+	ones := make([]float64, A.Size())
+	for i := range ones {
+		ones[i] = 1
+	}
+	X, _, err := system.solve(ctx, [][]float64{ones}, func(int) *DivergenceError {
+		return &DivergenceError{Target: "expected steps to absorption"}
+	})
+	if err != nil {
+		return fail(err)
+	}
+	t = X[0]
+
+	return
+}
+
+// transientLookup returns a lookup function, by original ID, of the values of the transient nodes.
+func transientLookup(values []float64, ttn translator) func(node uint32) (value float64, err error) {
+	return func(node uint32) (value float64, err error) {
+		t, err := ttn.ToNew(node)
+		if err != nil {
+			return
+		}
+		value = values[t]
+		return
+	}
+}
@@ -0,0 +1,183 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// artifactsMagic and artifactsVersion identify the binary format of the system description kept by WithArtifactsDir.
+const (
+	artifactsMagic   uint32 = 0x414d4341 //"AMCA"
+	artifactsVersion uint32 = 1
+)
+
+const (
+	artifactsSystemFile  = "system.amc"
+	artifactsBatchPrefix = "rhs-"
+	artifactsSystem      = "Ab.ptsc"
+)
+
+func artifactsBatchDir(dir string, offset int) string {
+	return filepath.Join(dir, fmt.Sprintf("%v%09d", artifactsBatchPrefix, offset))
+}
+
+// writeArtifacts writes in dir the description of the system solved for the results, along with the pruning settings,
+// removing the batches of previous runs.
+func writeArtifacts(dir string, r *Results, pr pruning, groups bool) (err error) {
+	path := filepath.Join(dir, artifactsSystemFile)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to create the artifacts directory %v.", dir)
+	}
+	batches, err := filepath.Glob(filepath.Join(dir, artifactsBatchPrefix+"*"))
+	if err != nil {
+		return errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to read the artifacts directory %v.", dir)
+	}
+	for _, b := range batches {
+		if err = os.RemoveAll(b); err != nil {
+			return errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to remove the previous batch %v.", b)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to create a file at %v.", path)
+	}
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	w := newBinaryWriter(f)
+	w.write(artifactsMagic, artifactsVersion)
+	w.write(pr.epsilon, int64(pr.k), groups)
+	w.write(r.Metadata.Nodes, r.Metadata.AbsorbingNodes)
+	w.writeBitmap(r.Report.Unreachable)
+	w.writeBitmap(r.absorbingNodes)
+	w.writeTranslator(r.ttn, r.probabilities.nT)
+	w.writeTranslator(r.tan, r.probabilities.nA)
+	if err = w.flush(); err != nil {
+		return errors.Wrapf(err, "AbsorbingMarkovChain Error: error while writing file at %v.", path)
+	}
+	return
+}
+
+// writeArtifactsBatch writes in dir the system of a batch of right hand sides.
+func writeArtifactsBatch(dir string, A *SparseMatrix, B [][]float64) (err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to create the artifacts directory %v.", dir)
+	}
+	return graph2Petsc(A, B, filepath.Join(dir, artifactsSystem))
+}
+
+// readArtifacts reads the description written by writeArtifacts, returning results without solutions.
+func readArtifacts(dir string) (r *Results, pr pruning, groups bool, err error) {
+	path := filepath.Join(dir, artifactsSystemFile)
+	fail := func(e error) (*Results, pruning, bool, error) {
+		r, pr, groups, err = nil, pruning{}, false, errors.Wrapf(e, "AbsorbingMarkovChain Error: error while reading file at %v.", path)
+		return r, pr, groups, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fail(err)
+	}
+	defer f.Close()
+
+	br := newBinaryReader(f)
+	var magic, version uint32
+	br.read(&magic, &version)
+	switch {
+	case br.err != nil:
+		return fail(br.err)
+	case magic != artifactsMagic || version != artifactsVersion:
+		return fail(errors.Errorf("unsupported format %x version %v", magic, version))
+	}
+
+	r = &Results{}
+	var k int64
+	br.read(&pr.epsilon, &k, &groups)
+	pr.k = int(k)
+	br.read(&r.Metadata.Nodes, &r.Metadata.AbsorbingNodes)
+	r.Report.Unreachable = roaring.BitmapOf(br.readUint32s()...)
+	r.absorbingNodes = roaring.BitmapOf(br.readUint32s()...)
+	ttn, tan := br.readUint32s(), br.readUint32s()
+	if br.err != nil {
+		return fail(br.err)
+	}
+	r.ttn, r.tan = myTranslator(ttn), myTranslator(tan)
+	r.probabilities.nT, r.probabilities.nA = len(ttn), len(tan)
+	r.Report.Stats = make(map[uint32]SolveStats, len(tan))
+	return
+}
+
+// Replay solves again the systems kept in dir by WithArtifactsDir, batch by batch, with the given solver and options,
+// DefaultSolver if nil: the files of each batch are read back and PETScSolver outputs are overwritten in place.
+func Replay(ctx context.Context, dir string, solver Solver, o SolverOptions) (results *Results, err error) {
+	fail := func(e error) (*Results, error) {
+		results, err = nil, e
+		return results, err
+	}
+
+	results, pr, groups, err := readArtifacts(dir)
+	if err != nil {
+		return fail(err)
+	}
+	if solver == nil {
+		solver = DefaultSolver("")
+	}
+	results.Metadata.SolverOptions = o.withDefaults()
+	results.Report.ArtifactsDir = dir
+	nA := results.probabilities.nA
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fail(errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to read the artifacts directory %v.", dir))
+	}
+	var A *SparseMatrix
+	var R [][]implicitWeightedEdge
+	for _, info := range infos { //sorted by name, thus by offset
+		name := info.Name()
+		if !info.IsDir() || !strings.HasPrefix(name, artifactsBatchPrefix) {
+			continue
+		}
+		offset, err := strconv.Atoi(strings.TrimPrefix(name, artifactsBatchPrefix))
+		if err != nil || offset != len(R) {
+			return fail(errors.Errorf("AbsorbingMarkovChain Error: unexpected batch directory %v.", name))
+		}
+
+		var B [][]float64
+		batchDir := filepath.Join(dir, name)
+		if A, B, err = petsc2System(filepath.Join(batchDir, artifactsSystem)); err != nil {
+			return fail(err)
+		}
+		if len(R)+len(B) > nA {
+			return fail(errors.Errorf("AbsorbingMarkovChain Error: batch directory %v exceeds the %v right hand sides of the system.", name, nA))
+		}
+		for _, b := range B {
+			R = append(R, sparseRHS(b))
+		}
+
+		batchSolver := solver
+		if ps, ok := solver.(PETScSolver); ok { //outputs in place
+			ps.WorkDir = batchDir
+			batchSolver = ps
+		}
+		if err = results.solveBatch(ctx, linearSystem{A: A, ttn: results.ttn, solver: batchSolver, options: o}, B, offset, pr, groups); err != nil {
+			return fail(err)
+		}
+	}
+	if A == nil || A.Size() != results.probabilities.nT || len(R) != nA {
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: incomplete artifacts in %v.", dir))
+	}
+	results.Metadata.SystemHash = systemHash(A, R, results.ttn, results.tan, len(R))
+
+	return
+}
@@ -0,0 +1,138 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// BlockSolver solves the linear systems block by block, following the strongly connected components of the transient
+// nodes in reverse topological order, so that each block depends only on blocks already solved. Single node blocks are
+// solved in closed form, blocks smaller than MinBlockSize by DenseSolver and the others by Solver.
+type BlockSolver struct {
+	Solver       Solver // solver of the large blocks, by default the one returned by DefaultSolver("")
+	MinBlockSize int    // minimum size of the blocks solved by Solver, by default 100
+}
+
+// Solve solves A·x = b for each right hand side b in B, the options are passed to the solver of the large blocks.
+// The stats of each right hand side report the total iterations over the blocks and the final residual norm of the whole system.
+func (s BlockSolver) Solve(ctx context.Context, A *SparseMatrix, B [][]float64, o SolverOptions) (X [][]float64, stats []SolveStats, err error) {
+	fail := func(e error) ([][]float64, []SolveStats, error) {
+		X, stats, err = nil, nil, e
+		return X, stats, err
+	}
+
+	solver, minBlockSize := s.Solver, s.MinBlockSize
+	if solver == nil {
+		solver = DefaultSolver("")
+	}
+	if minBlockSize < 1 {
+		minBlockSize = 100
+	}
+
+	n := A.Size()
+	nodes := roaring.NewBitmap()
+	nodes.AddRange(0, uint64(n))
+	g := dGraph{nodes, func(from uint32) []uint32 { return A.Cols[A.RowPtr[from]:A.RowPtr[from+1]] }}
+
+	X, stats = make([][]float64, len(B)), make([]SolveStats, len(B))
+	for i, b := range B {
+		if len(b) != n {
+			return fail(errors.Errorf("AbsorbingMarkovChain Error: right hand side of length %v for a system of size %v.", len(b), n))
+		}
+		X[i] = make([]float64, n)
+		stats[i].Reason = ConvergedIts
+	}
+
+	for blockID, c := range g.stronglyConnectedComponents() {
+		if err = ctx.Err(); err != nil {
+			return fail(err)
+		}
+
+		if len(c) == 1 { //closed form
+			i := c[0]
+			d := 0.0
+			for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+				if A.Cols[p] == i {
+					d += A.Values[p]
+				}
+			}
+			if d == 0 {
+				return fail(errors.Errorf("AbsorbingMarkovChain Error: zero pivot in row %v.", i))
+			}
+			for k, b := range B {
+				x := X[k]
+				v := b[i]
+				for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+					if j := A.Cols[p]; j != i {
+						v -= A.Values[p] * x[j]
+					}
+				}
+				x[i] = v / d
+			}
+			continue
+		}
+
+		//block sub-system, with the already solved nodes moved to the right hand sides
+		t := myTranslator(c)
+		Ac := &SparseMatrix{RowPtr: make([]int, 1, len(c)+1)}
+		Bc := make([][]float64, len(B))
+		for k := range Bc {
+			Bc[k] = make([]float64, len(c))
+		}
+		for ci, i := range c {
+			for k, b := range B {
+				Bc[k][ci] = b[i]
+			}
+			for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+				j := A.Cols[p]
+				if cj, err := t.ToNew(j); err == nil {
+					Ac.Cols = append(Ac.Cols, cj)
+					Ac.Values = append(Ac.Values, A.Values[p])
+					continue
+				}
+				for k := range B {
+					Bc[k][ci] -= A.Values[p] * X[k][j]
+				}
+			}
+			Ac.RowPtr = append(Ac.RowPtr, len(Ac.Cols))
+		}
+
+		direct := len(c) < minBlockSize
+		var blockSolver Solver = DenseSolver{}
+		switch ps, ok := solver.(PETScSolver); {
+		case direct:
+			//DenseSolver
+		case ok && ps.WorkDir != "": //keep the files of each block apart
+			ps.WorkDir = filepath.Join(ps.WorkDir, fmt.Sprintf("block-%09d", blockID))
+			blockSolver = ps
+		default:
+			blockSolver = solver
+		}
+		Xc, statsc, err := blockSolver.Solve(ctx, Ac, Bc, o)
+		if err != nil {
+			return fail(err)
+		}
+		for k, xc := range Xc {
+			for ci, i := range c {
+				X[k][i] = xc[ci]
+			}
+			st := &stats[k]
+			st.Iterations += statsc[k].Iterations
+			if st.Reason.Converged() && (!direct || !statsc[k].Reason.Converged()) {
+				st.Reason = statsc[k].Reason
+			}
+		}
+	}
+
+	//residual norms of the whole systems
+	r := make([]float64, n)
+	for k, b := range B {
+		stats[k].Residual = residualNorm(A, X[k], b, r)
+	}
+
+	return
+}
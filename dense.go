@@ -0,0 +1,92 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// DenseSolver solves the linear systems by Gaussian elimination with partial pivoting on a dense copy
+// of the matrix. It requires neither PETSc nor temporary files, so it is meant for unit tests and small chains.
+type DenseSolver struct{}
+
+// Solve solves A·x = b for each right hand side b in B, the options are ignored.
+// As PETSc direct solvers, each solve is reported as converged in one iteration.
+func (DenseSolver) Solve(ctx context.Context, A *SparseMatrix, B [][]float64, _ SolverOptions) (X [][]float64, stats []SolveStats, err error) {
+	n := A.Size()
+	LU := make([][]float64, n)
+	for i := range LU {
+		LU[i] = make([]float64, n)
+		for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+			LU[i][A.Cols[p]] += A.Values[p]
+		}
+	}
+
+	//LU factorization, with row permutation
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for k := 0; k < n; k++ {
+		if err = ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(LU[i][k]) > math.Abs(LU[pivot][k]) {
+				pivot = i
+			}
+		}
+		if LU[pivot][k] == 0 {
+			return nil, nil, errors.Errorf("AbsorbingMarkovChain Error: singular matrix, zero pivot in column %v.", k)
+		}
+		LU[k], LU[pivot] = LU[pivot], LU[k]
+		perm[k], perm[pivot] = perm[pivot], perm[k]
+		for i := k + 1; i < n; i++ {
+			l := LU[i][k] / LU[k][k]
+			LU[i][k] = l
+			for j := k + 1; j < n; j++ {
+				LU[i][j] -= l * LU[k][j]
+			}
+		}
+	}
+
+	X, stats = make([][]float64, len(B)), make([]SolveStats, len(B))
+	r := make([]float64, n)
+	for s, b := range B {
+		if len(b) != n {
+			return nil, nil, errors.Errorf("AbsorbingMarkovChain Error: right hand side of length %v for a system of size %v.", len(b), n)
+		}
+		x := make([]float64, n)
+		for i := range x { //forward substitution
+			x[i] = b[perm[i]]
+			for j := 0; j < i; j++ {
+				x[i] -= LU[i][j] * x[j]
+			}
+		}
+		for i := n - 1; i >= 0; i-- { //backward substitution
+			for j := i + 1; j < n; j++ {
+				x[i] -= LU[i][j] * x[j]
+			}
+			x[i] /= LU[i][i]
+		}
+		X[s] = x
+
+		stats[s] = SolveStats{ConvergedIts, 1, residualNorm(A, x, b, r)}
+	}
+
+	return
+}
+
+// residualNorm returns the norm of b - A·x, using r as buffer.
+func residualNorm(A *SparseMatrix, x, b, r []float64) float64 {
+	for i := range r {
+		r[i] = b[i]
+		for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+			r[i] -= A.Values[p] * x[A.Cols[p]]
+		}
+		r[i] *= r[i]
+	}
+	return math.Sqrt(fsum(r))
+}
@@ -2,143 +2,191 @@ package absorbingmarkovchain
 
 import (
 	"bufio"
-	"encoding/binary"
 	"os"
 	"sort"
 
 	"github.com/RoaringBitmap/roaring"
+	"github.com/ebonetti/absorbingmarkovchain/petscbin"
 	"github.com/pkg/errors"
 )
 
-func graph2Petsc(chain *AbsorbingMarkovChain, filepath string) (ttn, tan translator, err error) {
-	fail := func(e error) (translator, translator, error) {
-		ttn, tan, err = nil, nil, e
-		return ttn, tan, err
-	}
-
+func graph2Petsc(A *SparseMatrix, B [][]float64, filepath string) (err error) {
 	Ab, err := os.Create(filepath)
 	if err != nil {
-		return fail(errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to create a temporary file at %v.", filepath))
+		return errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to create a temporary file at %v.", filepath)
 	}
 	defer func() {
 		if e := Ab.Close(); e != nil && err == nil {
-			/*return*/ fail(e)
+			err = e
 		}
 	}()
 
 	w := bufio.NewWriter(Ab)
 	defer func() {
 		if e := w.Flush(); e != nil && err == nil {
-			/*return*/ fail(e)
+			err = e
 		}
 	}()
 
-	write := func(vv ...interface{}) {
-		for _, v := range vv {
-			if err != nil {
-				return
-			}
-			//_,err = fmt.Fprintln(w,v)
-			err = binary.Write(w, binary.BigEndian, v)
+	n := A.Size()
+	err = petscbin.WriteMat(w, petscbin.Mat{M: n, N: n, RowPtr: A.RowPtr, Cols: A.Cols, Values: A.Values})
+	for _, b := range B {
+		if err != nil {
+			break
 		}
-
+		err = petscbin.WriteVec(w, b)
 	}
 
-	ttn, tan, e := _graph2Petsc(chain, write)
 	if err != nil {
-		return fail(errors.Wrapf(err, "AbsorbingMarkovChain Error: error while writing file at %v.", filepath))
+		return errors.Wrapf(err, "AbsorbingMarkovChain Error: error while writing file at %v.", filepath)
 	}
-	err = e
 
 	return
 }
 
-const matFileClassID int32 = 1211216
-const vecFileClassID int32 = 1211214
+// graph2System builds the linear system (I-Q)·X = R, with A in compressed sparse row format and the sparse columns of R,
+// one right hand side for each target absorbing node, all the assignable ones if targets is nil. If groups is not nil, its keys are
+// the targets and their columns of R are summed into one right hand side for each group; tan then translates group IDs.
+func graph2System(chain *AbsorbingMarkovChain, targets *roaring.Bitmap, groups map[uint32]uint32) (A *SparseMatrix, R [][]implicitWeightedEdge, ttn, tan translator, err error) {
+	fail := func(e error) (*SparseMatrix, [][]implicitWeightedEdge, translator, translator, error) {
+		A, R, ttn, tan, err = nil, nil, nil, nil, e
+		return A, R, ttn, tan, err
+	}
 
-func _graph2Petsc(chain *AbsorbingMarkovChain, write func(...interface{})) (ttn, tan translator, err error) {
-	fail := func(e error) (t1, t2 translator, err error) {
-		ttn, tan, err = nil, nil, e
-		return ttn, tan, err
+	group := func(node uint32) uint32 { return node }
+	if groups != nil {
+		groupIDs := roaring.NewBitmap()
+		targets = roaring.NewBitmap()
+		for node, g := range groups {
+			targets.Add(node)
+			groupIDs.Add(g)
+		}
+		group = func(node uint32) uint32 { return groups[node] }
+		tan = newTranslator(groupIDs)
 	}
-	g := chain.filterNodes(chain.absorbingNodes).addSelfLoops()
-	n := uint32(g.Nodes.GetCardinality())
-	entries, rowEntries := uint32(0), make([]uint32, 0, n)
-	for i := g.Nodes.Iterator(); i.HasNext(); {
-		from := i.Next()
-		l := uint32(len(g.Edges(from)))
-		entries += l
-		rowEntries = append(rowEntries, l)
+	assignable := chain.assignableNodes()
+	switch {
+	case targets == nil:
+		targets = assignable
+	case targets.IsEmpty():
+		return fail(errors.New("AbsorbingMarkovChain Error: no target absorbing nodes"))
+	case !roaring.AndNot(targets, assignable).IsEmpty():
+		v, _ := roaring.AndNot(targets, assignable).Select(0)
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: %v is not an absorbing node.", v))
+	}
+	if tan == nil {
+		tan = newTranslator(targets)
 	}
-	g, ttn = g.normalizedIDs()
 
-	wg, err := chain.normalizedWeights()
+	A, ttn, wg, err := graph2Matrix(chain)
 	if err != nil {
 		return fail(err)
 	}
 
-	cb, err := compressedB(&AbsorbingMarkovChain{wg, chain.absorbingNodes, chain.tmpDir})
-	if err != nil {
+	if R, err = absorbingRHS(wg, chain.absorbingNodes, targets, ttn, tan, group); err != nil {
+		return fail(err)
+	}
+
+	return
+}
+
+// graph2Matrix builds the (I-Q) matrix in compressed sparse row format, along with the graph with normalized weights.
+func graph2Matrix(chain *AbsorbingMarkovChain) (A *SparseMatrix, ttn translator, wg wDGraph, err error) {
+	fail := func(e error) (*SparseMatrix, translator, wDGraph, error) {
+		A, ttn, wg, err = nil, nil, wDGraph{}, e
+		return A, ttn, wg, err
+	}
+	g := chain.filterNodes(chain.absorbingNodes).addSelfLoops()
+	n := int(g.Nodes.GetCardinality())
+	g, ttn = g.normalizedIDs()
+
+	if wg, err = chain.normalizedWeights(); err != nil {
 		return fail(err)
 	}
-	wg = wg.addSelfLoops()
-	wg.dGraph = wg.dGraph.filterNodes(chain.absorbingNodes)
-
-	/*
-			MAT_FILE_CLASSID //matrix file identifier
-			n               //number of rows
-			n,         //number of columns
-		    entries,   //total number of nonzeros
-			rowEntries,//number nonzeros in each row
-			indices,   //column indices of all nonzeros
-			values,    //values of all nonzeros
-	*/
-	write(matFileClassID, n, n, entries, rowEntries)
+	lwg := wg.addSelfLoops()
+	lwg.dGraph = lwg.dGraph.filterNodes(chain.absorbingNodes)
+
+	A = &SparseMatrix{RowPtr: make([]int, 1, n+1)}
 	for i := g.Nodes.Iterator(); i.HasNext(); {
-		from := i.Next()
-		if to := g.Edges(from); len(to) > 0 {
-			write(to)
-		}
+		A.Cols = append(A.Cols, g.Edges(i.Next())...)
+		A.RowPtr = append(A.RowPtr, len(A.Cols))
 	}
-	for i := wg.Nodes.Iterator(); i.HasNext(); {
+	A.Values = make([]float64, 0, len(A.Cols))
+	for i := lwg.Nodes.Iterator(); i.HasNext(); {
 		from := i.Next()
-		for _, to := range wg.Edges(from) {
-			w, err := wg.Weighter(from, to)
+		for _, to := range lwg.Edges(from) {
+			w, err := lwg.Weighter(from, to)
 			if err != nil {
 				return fail(err)
 			}
-			write(w)
+			A.Values = append(A.Values, -w)
 		}
 	}
 
-	b := make([]float64, 0, n)
-	for i := chain.absorbingNodes.Iterator(); i.HasNext(); {
-		b = b[:0]
-		for _, e := range cb[i.Next()] {
-			p, err := ttn.ToNew(e.to)
-			if err != nil {
-				return fail(err)
-			}
-			for uint32(len(b)) < p {
-				b = append(b, 0)
-			}
-			b = append(b, e.w)
+	return
+}
+
+// absorbingRHS builds the columns of R from the graph with normalized weights, as the transient nodes (new IDs) with
+// their weights: the column of each target absorbing node is added to the right hand side of its group, translated by tan.
+func absorbingRHS(wg wDGraph, absorbingNodes, targets *roaring.Bitmap, ttn, tan translator, group func(uint32) uint32) (R [][]implicitWeightedEdge, err error) {
+	cb, err := compressedB(wg, absorbingNodes, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	R = [][]implicitWeightedEdge{}
+	for i := targets.Iterator(); i.HasNext(); {
+		a := i.Next()
+		g, err := tan.ToNew(group(a))
+		if err != nil {
+			return nil, err
 		}
-		for uint32(len(b)) < n {
-			b = append(b, 0)
+		for int(g) >= len(R) {
+			R = append(R, nil)
+		}
+		for _, e := range cb[a] {
+			if e.to, err = ttn.ToNew(e.to); err != nil {
+				return nil, err
+			}
+			R[g] = append(R[g], e)
 		}
+	}
 
-		/*
-		   VEC_FILE_CLASSID, //vector file identifier
-		   n,         //number of rows
-		   b,    //values of all entries
-		*/
-		write(vecFileClassID, n)
-		write(b)
+	for g, r := range R { //sum the entries of the same transient node
+		sort.SliceStable(r, func(i, j int) bool { return r[i].to < r[j].to })
+		merged := r[:0]
+		for _, e := range r {
+			if l := len(merged); l > 0 && merged[l-1].to == e.to {
+				merged[l-1].w += e.w
+				continue
+			}
+			merged = append(merged, e)
+		}
+		R[g] = merged
 	}
 
-	tan = newTranslator(chain.absorbingNodes)
+	return
+}
+
+// sparseRHS compresses a right hand side to its nonzero entries.
+func sparseRHS(b []float64) (r []implicitWeightedEdge) {
+	for i, w := range b {
+		if w != 0 {
+			r = append(r, implicitWeightedEdge{uint32(i), w})
+		}
+	}
+	return
+}
 
+// denseRHS expands the columns of R to right hand sides of size n.
+func denseRHS(R [][]implicitWeightedEdge, n int) (B [][]float64) {
+	B = make([][]float64, len(R))
+	for i, r := range R {
+		B[i] = make([]float64, n)
+		for _, e := range r {
+			B[i][e.to] = e.w
+		}
+	}
 	return
 }
 
@@ -147,12 +195,12 @@ type implicitWeightedEdge struct {
 	w  float64
 }
 
-func compressedB(chain *AbsorbingMarkovChain) (cb map[uint32][]implicitWeightedEdge, err error) {
+func compressedB(g wDGraph, absorbingNodes, targets *roaring.Bitmap) (cb map[uint32][]implicitWeightedEdge, err error) {
 	cb = map[uint32][]implicitWeightedEdge{}
-	for i := roaring.AndNot(chain.Nodes, chain.absorbingNodes).Iterator(); i.HasNext(); {
+	for i := roaring.AndNot(g.Nodes, absorbingNodes).Iterator(); i.HasNext(); {
 		from := i.Next()
-		to := roaring.BitmapOf(chain.Edges(from)...)
-		to.And(chain.absorbingNodes)
+		to := roaring.BitmapOf(g.Edges(from)...)
+		to.And(targets)
 		for i := to.Iterator(); i.HasNext(); {
 			to := i.Next()
 			tt := cb[to]
@@ -160,13 +208,47 @@ func compressedB(chain *AbsorbingMarkovChain) (cb map[uint32][]implicitWeightedE
 			tt = append(tt, implicitWeightedEdge{})
 			copy(tt[p+1:], tt[p:])
 			e := implicitWeightedEdge{to: from}
-			if e.w, err = chain.Weighter(from, to); err != nil {
+			if e.w, err = g.Weighter(from, to); err != nil {
 				return
 			}
-			e.w = -e.w
 			tt[p] = e
 			cb[to] = tt
 		}
 	}
 	return
 }
+
+// petsc2System reads the linear system written by graph2Petsc.
+func petsc2System(filepath string) (A *SparseMatrix, B [][]float64, err error) {
+	fail := func(e error) (*SparseMatrix, [][]float64, error) {
+		A, B, err = nil, nil, errors.Wrapf(e, "AbsorbingMarkovChain Error: error while reading file at %v.", filepath)
+		return A, B, err
+	}
+
+	Ab, err := os.Open(filepath)
+	if err != nil {
+		return fail(err)
+	}
+	defer Ab.Close()
+	r := bufio.NewReader(Ab)
+
+	m, err := petscbin.ReadMat(r)
+	switch {
+	case err != nil:
+		return fail(err)
+	case m.M != m.N:
+		return fail(errors.New("not a square matrix"))
+	}
+	A = &SparseMatrix{RowPtr: m.RowPtr, Cols: m.Cols, Values: m.Values}
+
+	if B, err = petscbin.ReadVecs(r); err != nil {
+		return fail(err)
+	}
+	for _, b := range B {
+		if len(b) != m.M {
+			return fail(errors.New("vector size doesn't match the system one"))
+		}
+	}
+
+	return
+}
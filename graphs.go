@@ -1,6 +1,8 @@
 package absorbingmarkovchain
 
 import (
+	"sort"
+
 	"github.com/RoaringBitmap/roaring"
 )
 
@@ -89,6 +91,104 @@ func (gin dGraph) normalizedIDs() (gout dGraph, t translator) {
 	return
 }
 
+// stronglyConnectedComponents returns the strongly connected components of the graph in reverse topological order,
+// that is each component comes after all the components it has arcs towards. Arcs towards nodes not in the graph are ignored.
+func (g dGraph) stronglyConnectedComponents() (components [][]uint32) {
+	//iterative version of Tarjan's algorithm, on normalized IDs
+	t := newTranslator(g.Nodes)
+	n := int(g.Nodes.GetCardinality())
+	const unvisited = -1
+	index, lowlink, onStack := make([]int, n), make([]int, n), make([]bool, n)
+	for i := range index {
+		index[i] = unvisited
+	}
+
+	type frame struct {
+		v  int
+		to []uint32
+	}
+	stack, callStack, counter := []int{}, []frame{}, 0
+	visit := func(v int) {
+		index[v], lowlink[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+		oldID, _ := t.ToOld(uint32(v))
+		callStack = append(callStack, frame{v, g.Edges(oldID)})
+	}
+
+	for root := 0; root < n; root++ {
+		if index[root] != unvisited {
+			continue
+		}
+		visit(root)
+		for len(callStack) > 0 {
+			f := &callStack[len(callStack)-1]
+			if len(f.to) > 0 {
+				w, err := t.ToNew(f.to[0])
+				f.to = f.to[1:]
+				switch {
+				case err != nil:
+					//not a graph node
+				case index[w] == unvisited:
+					visit(int(w))
+				case onStack[w] && index[w] < lowlink[f.v]:
+					lowlink[f.v] = index[w]
+				}
+				continue
+			}
+
+			v := f.v
+			callStack = callStack[:len(callStack)-1]
+			if len(callStack) > 0 {
+				if u := callStack[len(callStack)-1].v; lowlink[v] < lowlink[u] {
+					lowlink[u] = lowlink[v]
+				}
+			}
+			if lowlink[v] != index[v] {
+				continue
+			}
+			p := len(stack) - 1
+			for stack[p] != v {
+				p--
+			}
+			component := make([]uint32, 0, len(stack)-p)
+			for _, w := range stack[p:] {
+				onStack[w] = false
+				oldID, _ := t.ToOld(uint32(w))
+				component = append(component, oldID)
+			}
+			sort.Slice(component, func(i, j int) bool { return component[i] < component[j] })
+			components = append(components, component)
+			stack = stack[:p]
+		}
+	}
+
+	return
+}
+
+// closedClasses returns the nodes of the strongly connected components, with more than one node, without arcs leaving them.
+func (g dGraph) closedClasses() (nodes *roaring.Bitmap) {
+	nodes = roaring.NewBitmap()
+	for _, c := range g.stronglyConnectedComponents() {
+		if len(c) < 2 {
+			continue
+		}
+		closed := true
+		for _, from := range c {
+			for _, to := range g.Edges(from) {
+				if match, _ := uint32Exist(c, to); !match && g.Nodes.Contains(to) {
+					closed = false
+				}
+			}
+		}
+		if closed {
+			nodes.AddMany(c)
+		}
+	}
+	return
+}
+
 type wDGraph struct {
 	dGraph
 	Weighter func(from, to uint32) (weight float64, err error)
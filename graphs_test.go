@@ -0,0 +1,24 @@
+package absorbingmarkovchain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	//{1,2,3} reaches {4,5}, which reaches 6; 7 is isolated and 8 isn't a graph node
+	m := map[uint32][]uint32{1: {2}, 2: {3, 4}, 3: {1}, 4: {5}, 5: {4, 6, 8}}
+	g := dGraph{roaring.BitmapOf(1, 2, 3, 4, 5, 6, 7), func(from uint32) []uint32 { return m[from] }}
+
+	expected := [][]uint32{{6}, {4, 5}, {1, 2, 3}, {7}}
+	if c := g.stronglyConnectedComponents(); !reflect.DeepEqual(c, expected) {
+		t.Errorf("Strongly connected components should be %v, while are %v", expected, c)
+	}
+
+	m[5] = []uint32{4, 8}
+	if c := g.closedClasses(); !c.Equals(roaring.BitmapOf(4, 5)) {
+		t.Errorf("Closed classes should contain 4 and 5, while contain %v", c)
+	}
+}
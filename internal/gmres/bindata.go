@@ -80,42 +80,51 @@ func (fi bindataFileInfo) Sys() interface{} {
 }
 
 var _bindataGmrespetscGMRESc = []byte(
-	"\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x56\x7f\x6f\xdb\x36\x10\xfd\x5f\x9f\xe2\xa6\x01\x86\x6c\xb0\x76\x02" +
-	"\x6c\xc3\x30\x2f\x1d\x1c\xc7\x6e\x82\xc4\xb5\x26\x65\xe9\x80\xb6\x30\x28\xe9\x24\x13\x91\x44\x82\xa4\xd3\xb8\x43" +
-	"\xbe\xfb\x40\xca\xbf\x2d\x39\x01\xaa\x3f\x84\x44\xbc\x77\xef\xf1\xf1\xee\x68\xa5\xa9\x66\x31\xc4\x73\x2a\x61\x8e" +
-	"\xb9\xf8\xfc\x15\x2e\xc0\xfd\x47\x21\x7c\x98\x04\xa3\x10\x34\x07\xc5\xf3\x27\x84\x9c\x95\x48\x25\xa8\xa5\xd2\x58" +
-	"\xa8\x2f\xe5\x97\xd2\xed\x3b\xce\xcf\xac\x8c\xf3\x45\x82\xf0\xa7\x40\xad\xe2\x47\x25\xba\xf3\xf7\x87\x5f\x23\x9a" +
-	"\x99\xaf\x8e\xa3\x97\x02\x13\x4c\x41\x69\xb9\x88\xf5\x7f\x0e\x00\x54\xcc\xdb\x87\xa5\x25\x2d\xf0\xb3\x3f\xba\x0f" +
-	"\x87\xb3\xc9\xe0\xdf\x99\x3f\xb8\xbf\x9e\xdd\x8d\x3e\x7e\x25\xbc\x71\xa9\xef\xbc\x80\x4f\x25\x2d\x50\xa3\xec\x3b" +
-	"\x0e\x2b\x35\x14\x94\x95\x9e\xf9\x83\xca\x2c\x26\x96\xa5\xd3\xa1\x32\x7b\x6a\x43\x45\xec\x1b\x69\x0f\x0c\xbf\xa1" +
-	"\xac\x88\x13\xc2\xd3\xa4\x0f\xc7\x4f\xaf\x97\xb2\x1c\xe1\xc9\xc6\x5a\xec\x03\xc6\xbb\x01\x51\x1d\x6a\x8d\x0d\xae" +
-	"\x43\xa0\x65\x02\x54\x08\xc9\x9f\x8d\x9b\x0b\xcd\x78\x09\x9e\x9a\x53\xc9\xca\x0c\x0a\x2c\xb8\x5c\xb6\x6d\xe2\x09" +
-	"\xd5\xbb\xe8\xc1\xa9\xc4\x7b\x27\x02\x05\xd5\x92\x3d\xdb\x24\xb7\xa1\xbf\x1b\xf9\xa8\x44\x53\x9a\x6d\x12\x73\xc6" +
-	"\x12\x62\x5e\x6a\x7c\xd6\x95\x3f\xc3\xbd\x50\x11\x37\x6a\xe9\xf5\xfc\xe1\x3e\xd4\x58\x3b\x92\x92\xcb\x21\x4f\x10" +
-	"\x18\x4a\xd9\xaf\x16\xd6\x87\x54\x01\x3b\xc2\xfc\xaf\xfa\x5b\xd0\x25\xcd\xd6\x9e\xd2\xac\xef\xd8\x05\x03\x87\x8b" +
-	"\x6a\xfd\xa6\x64\x9a\xd1\x9c\x7d\x47\xaf\x65\x0f\xd6\xbc\x9f\x88\x67\xce\xb7\xd3\x3e\x23\xa6\x84\xdb\xfd\xe1\xf5" +
-	"\xed\x28\x08\xfe\xf6\x0c\xb2\x6d\x65\x1f\x67\xba\xa4\xd9\x50\x22\xd5\xe8\x55\x05\x35\x9c\x4e\x26\xb3\x4f\xd3\xe0" +
-	"\xee\x8a\x28\xf6\x1d\x79\xea\x6d\xc4\xb6\x49\x2b\xa2\xd9\x61\xda\xba\x8c\x1f\x50\x5f\x51\x4d\xbd\x88\x66\xc4\x7b" +
-	"\xe2\x2c\xe9\x74\xda\xad\x6a\x93\x47\xf0\x3a\x7c\x88\xfa\x23\x2d\xd0\xe2\xdd\x0d\xff\x25\xcd\x5c\xe2\x1a\x83\x29" +
-	"\x2b\x15\x88\xf5\x77\x05\x29\x97\xa0\x62\xc9\x84\x76\xdf\x22\x2f\xc0\x8c\x29\x8d\x32\xd4\xa6\xf0\x2c\xcb\x4a\xdd" +
-	"\xbb\xf7\x55\xe3\x91\xe3\xee\x22\xee\x20\xea\x0a\xad\x62\x97\xb8\x2c\x75\x89\x6b\x14\x02\x4f\x81\x95\x62\xa1\xc1" +
-	"\xb6\x86\x79\xfd\xb0\x02\xde\xac\x40\xf1\xbc\x5b\x50\x9d\xd3\xc8\x25\x2e\xdf\x15\xc1\x17\xfa\x15\x15\x4e\x55\xa2" +
-	"\x30\x15\x58\xee\x68\x3e\x52\x57\x8d\x82\x4b\x56\x52\xb9\x34\xb1\xc7\x75\x71\x60\xd5\xf8\xe6\x6e\x34\x9b\x4c\xaf" +
-	"\x46\xb3\x60\x34\xb8\x22\x2d\x96\x26\xb5\x16\xac\xb9\x77\xa4\x36\x90\x0f\xc2\xe1\xcd\xcd\x69\xee\x95\x49\x2d\x6e" +
-	"\xc8\xe0\x15\xc3\xab\xac\xfe\x42\xcd\xc7\x5c\x16\x54\x7b\x3c\x4d\x56\xfe\x3e\xdc\x8c\x3e\x8d\x82\x99\x65\x9c\x4d" +
-	"\x06\xf7\x77\x83\xcb\xe3\x7c\x6b\xf9\x77\x9c\x26\xa0\xe7\xb8\x1a\x32\xdd\x5d\xa2\x09\xd5\x4d\x7d\xd4\x1a\x9c\x2a" +
-	"\x89\x09\xd5\x26\xaf\x37\x20\x75\xc6\xad\xa9\xc3\x6a\x30\x71\x61\x66\xa6\xb2\x83\x54\xf3\x1c\x25\x2d\x63\x54\x7b" +
-	"\x42\x6e\x43\xbf\x51\xc8\xa3\x3a\x9a\x0a\x07\xd0\x10\xf5\xfd\x52\xa0\xf7\xa8\x04\xb9\x0d\x7d\x7b\xf9\x1d\x42\x7a" +
-	"\xbd\x68\x09\x09\xa6\x74\x91\xeb\xee\x21\x77\x88\x7a\x2a\x50\x52\xcd\xa5\xb2\x59\x06\xe4\xe4\xf6\x57\x9c\x9b\xbd" +
-	"\x58\xcc\x39\xbe\xfb\xdd\xbc\xce\x7f\x23\xe7\xf8\x0b\xf9\xf5\xec\xec\xe4\xd8\x30\x42\x51\xfb\x43\x8b\x6d\x89\xf8" +
-	"\x64\x07\x0e\xd7\x5b\x14\x31\xf1\x87\xe1\x34\x78\x2d\x75\x88\x7a\x2c\x79\x31\xad\xac\xf7\xea\x3c\xdc\x45\x3c\x60" +
-	"\xdc\xe8\x7f\x54\xab\xcc\x4c\x2f\x6f\x8b\xb6\xd5\x10\x55\xd5\x00\x3f\xd9\x4b\x03\x6a\x57\xdb\xd5\x05\x7e\xa0\xd6" +
-	"\x14\x8a\x35\x22\x22\xf5\x74\xfb\x5a\x4d\x6f\x78\x11\xe1\x0d\x4d\xfb\x62\xdf\xbb\x0b\x70\x71\x01\xd5\xce\x46\x41" +
-	"\x30\xb3\xcd\x6f\xfa\xfe\x2f\x38\x83\x3f\x60\xbf\x6a\xc7\x12\x11\xbe\x71\xf9\x08\x4a\xd0\x18\xbb\x0d\x8d\x79\x85" +
-	"\x4a\x4b\xbe\xf4\x1a\x27\xc7\x31\x64\x9c\x2f\xd4\xdc\x6b\x12\x5d\xd3\xfb\x5c\x6c\x5b\xff\xad\x03\x63\xa3\xeb\x15" +
-	"\x9e\xdb\xd0\xdf\x84\xbe\xd2\x61\x0f\x18\x6f\x42\xeb\x4f\x67\x3b\x15\x36\x81\x27\xfb\x67\x7d\xa3\x6c\xd3\xbe\xe1" +
-	"\x8a\x1e\xb3\xb2\xfa\xf1\xb0\x5a\x93\xa8\x17\xb2\x84\xb3\xbe\xf3\xe2\xfc\x1f\x00\x00\xff\xff\x43\x55\x5b\x1b\x0a" +
-	"\x0b\x00\x00")
+	"\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x57\x7d\x6f\xe2\x38\x13\xff\x9f\x4f\x31\x4f\x1e\x1d\x0a\x95\x17\x5a" +
+	"\xe9\xde\x74\x5c\xf7\x44\x29\x74\x51\x4b\xc9\x25\xbd\xee\x9d\x76\x57\xc8\x49\x26\xa9\xd5\xc4\x8e\x6c\xd3\x96\x3d" +
+	"\xed\x77\x3f\xd9\x49\x28\x6f\xa1\x48\x77\xa4\xa2\x10\xfb\xf7\x92\xf1\x78\x3c\x28\x4d\x35\x8b\x20\x7a\xa0\x12\x1e" +
+	"\x30\x2b\x3e\x7d\x81\x73\x70\xfe\x50\x08\x57\x53\x7f\x14\x80\x16\xa0\x44\xf6\x84\x90\x31\x8e\x54\x82\x5a\x2a\x8d" +
+	"\xb9\xfa\xcc\x3f\x73\xa7\xdf\x6a\xfd\x9f\xf1\x28\x5b\xc4\x08\xbf\x16\xa8\x55\xf4\xa8\x8a\xee\xc3\xfb\xed\xbb\x21" +
+	"\x4d\xcd\xdd\x56\x4b\x2f\x0b\x8c\x31\x01\xa5\xe5\x22\xd2\x7f\xb7\x00\xa0\x54\x7e\x7d\xb1\x84\xd3\x1c\x3f\x79\xa3" +
+	"\xbb\x60\x38\x9f\x0e\xfe\x9c\x7b\x83\xbb\x0f\xf3\x9b\xd1\xed\x17\x22\x9a\x87\x64\xe3\x50\xbf\xf5\x0d\x3c\x2a\x69" +
+	"\x8e\x1a\x65\xbf\xd5\x62\x5c\x43\x4e\x19\x77\xcd\x07\x2a\xd3\x88\x58\x03\x27\x27\x54\xa6\x4f\x1d\x28\x3d\x79\xc6" +
+	"\xf5\x3d\xc3\x67\xb4\xd6\x58\x12\x13\x91\xc4\xfd\xda\xe3\xda\xab\xd7\x4b\x58\x86\xf0\x64\xe7\x5a\xec\x78\x72\x33" +
+	"\xaa\x47\x01\xe0\x44\xee\x07\x5a\xac\xc4\x42\x48\x0d\x86\xc2\x62\xaf\x03\x6f\x28\xf8\x13\xca\x14\x63\x1f\xa9\x12" +
+	"\x1c\xa4\xfd\xb7\xc3\xd0\xeb\x95\xab\x22\x16\x3a\x12\x39\xbe\xba\x9e\x70\x5d\x4d\x61\x5a\x35\x08\xaf\xd0\x4c\xa3" +
+	"\xa4\x9a\x09\xae\x5e\x09\x7c\xa4\x59\x49\x20\xb9\x90\x79\xff\x20\x41\xc2\x38\xcd\x40\xa2\x62\xf1\x82\x66\x60\x00" +
+	"\x96\xe9\x1e\xa3\x7a\xaa\xb9\xc2\x26\x23\x86\xc9\xff\x10\x00\xe5\x31\xd0\xa2\x90\xe2\xc5\x64\xdb\xc2\x58\x02\x57" +
+	"\x3d\x50\xc9\x78\x0a\x39\xe6\x42\x2e\x3b\x96\x78\x4a\xeb\xc7\xb3\xd7\xe0\x10\xf1\x46\xc6\x42\x4e\xb5\x64\x2f\x75" +
+	"\x98\xeb\x59\xe6\x7a\x54\x45\xff\x4d\x12\x13\x6d\x09\x91\xe0\x1a\x5f\xb4\x65\xf1\x86\xf5\x2c\x7b\x15\x51\xa3\x97" +
+	"\x5e\xcf\x1b\x6e\x42\x4d\xa0\x47\x52\x0a\x39\x14\x31\x02\x43\x29\xfb\xe5\x40\x9d\xa9\x55\xf2\x14\xe6\xbb\xaa\xc6" +
+	"\x0c\xe8\x82\xa6\x15\x69\x48\xd3\x7e\xcb\x0e\x18\x38\x9c\xd7\xcb\xcf\x34\xa3\x19\xfb\x8a\x6e\xdb\x66\xb7\x79\x7f" +
+	"\x22\xae\x49\xf2\x93\xce\x29\x31\x5b\xbc\xd3\x1f\x7e\xb8\x1e\xf9\xfe\xef\xae\x41\x76\xac\xed\x5d\xa6\x0b\x9a\x0e" +
+	"\x25\x52\x8d\x6e\xb9\xab\x86\xb3\xe9\x74\xfe\x71\xe6\xdf\x5c\x12\xc5\xbe\xa2\x48\xdc\x95\xd9\x0e\x69\x87\x34\xdd" +
+	"\xa6\xdd\xc7\x78\x85\xfa\x92\x6a\xea\x86\x34\x25\xee\x93\x60\xf1\xc9\x49\xa7\x5d\x3e\xe4\x0e\x7c\x1f\x3e\x40\x7d" +
+	"\x4b\x73\xb4\x78\x67\xa5\x7f\x41\x53\x87\x38\x26\xc0\x94\x71\x05\x45\x7d\x5f\x41\x22\x24\xa8\x48\xb2\x42\x3b\xc7" +
+	"\xd8\xf3\x31\x65\x4a\xa3\x0c\xb4\x49\x3c\xab\x52\xb9\x7b\xf7\xbe\x2c\x4c\x64\xb7\xc4\x10\x67\x10\x76\x0b\xad\x22" +
+	"\x87\x38\x2c\x71\x88\x63\x1c\x82\x48\x80\xf1\x62\x51\x6e\x6e\xfb\xf6\xaf\x1d\x88\x66\x07\x4a\x64\xb5\x05\xb1\x6e" +
+	"\x41\x2c\xf4\x7f\xeb\x41\x36\x7b\x28\x8b\x59\x57\xbf\x68\x87\x38\x72\xdd\xc5\x5a\x99\x6b\x70\x61\x6d\xf4\x7a\x30" +
+	"\x2b\x90\xaf\xc5\x6d\xc7\x5d\x59\x93\x2f\x18\xa7\x72\x69\xe6\xee\xe6\xe6\xd6\x72\x99\x6a\x3c\x9f\xce\x2e\x47\x73" +
+	"\x7f\x34\xb8\x24\x6d\x96\xc4\x7b\x43\x50\x6b\xaf\x05\x8c\x40\x68\x75\xe0\x99\xe9\x07\xb1\xd0\xa0\x1f\x10\xba\x8c" +
+	"\x27\x02\x22\x91\x17\x94\x9b\x22\x75\xc0\x65\xd3\xee\x69\x8b\x06\x0f\xbb\x14\x01\xea\xbb\x65\x81\xae\x48\xe2\x32" +
+	"\xe4\xf7\x93\xd1\xc7\x91\x7f\x31\xb9\x1d\xf8\x7f\x1d\xc9\x31\x66\x19\x06\xa8\xa7\x22\x2e\x79\x5e\x23\xf2\xd1\x9f" +
+	"\xdc\x8d\x8e\x64\x29\x43\x1e\x3c\xb2\x62\xc2\x13\xe1\x1e\xff\x08\x95\xbc\x49\x05\x83\x5a\xad\x4f\x99\xcc\x07\xd7" +
+	"\x62\xfb\x74\x5c\x27\x1f\x1f\x5e\xfd\x2a\x4d\x9d\x67\x87\xb4\xa5\xf1\x0a\x5b\x3a\xb5\xd0\x8d\xa0\xb1\x5d\xd8\xf2" +
+	"\x78\xe8\xae\x0b\x4d\xa9\x6e\x5c\xc3\xc1\xa1\xc7\x9f\x52\x6d\x78\xdd\x01\xd9\x97\x6e\xb5\x74\x50\x1e\x29\xa2\x30" +
+	"\xa7\x9d\xb2\x47\xa0\x16\x19\x4a\xca\x23\x54\x1b\x46\x4c\x5b\xd0\x64\xe4\x51\x15\x87\xac\x5c\x07\x5e\x9d\x44\x8f" +
+	"\xaa\x20\xd7\x81\x67\xdb\xba\x6d\x48\xaf\x17\x2e\x21\xc6\x84\x2e\x32\xdd\xdd\xd6\x0e\x50\xcf\x0a\xd3\x28\x08\xa9" +
+	"\x2c\xcb\x80\x0c\x8e\xd0\x5c\x3d\x8b\xc5\x9c\xe1\xbb\x9f\xc9\x19\xbe\x3b\xfb\x91\x9c\xe1\xf7\xe4\x87\xd3\xd3\x1d" +
+	"\x8a\x2d\x8e\x2b\xd4\xde\xd0\x62\xdb\x45\x74\x48\xcf\x1b\xd6\x8f\x58\x44\xc4\x1b\x06\x33\xff\x2d\xea\x00\xf5\x58" +
+	"\x8a\x7c\x56\x86\xde\xdd\x17\xc3\x75\xc4\x3d\x46\x8d\xf1\x0f\x77\x90\x06\x68\xce\x1d\xf7\x15\x6d\xb3\x21\x2c\xb3" +
+	"\x01\xfe\x67\xee\xf7\x61\xef\x68\xa7\xec\x3f\xb7\xdc\x9a\x44\x31\x26\x49\x48\xf6\xcb\x6d\x7a\x35\xb5\xc3\x0d\x49" +
+	"\xd3\x1e\xad\xd2\xcf\x2f\x77\x57\x54\xf5\x9b\x3c\xc2\xaa\xd7\x24\x6b\x6d\xa1\xcd\xca\xdd\xf6\x6e\xd3\xdf\x15\xea" +
+	"\xad\xae\xd5\x9a\x6d\x97\x74\x6f\x19\x2e\x57\x7a\x52\x4b\xde\x2e\xf2\x10\x65\x49\xc0\xb4\x3a\x0e\xed\x57\x0e\x6f" +
+	"\x85\xcc\x2b\x6d\xd3\x8a\xbe\x05\xb6\x65\x6a\xec\x49\xc6\x75\xb2\xbb\xb4\x32\x89\x89\xf3\x5d\x0c\xe6\xaf\x7b\xf6" +
+	"\x53\xfa\x99\x3b\xc4\xfc\x6a\xe8\x54\x61\xb2\x9f\x99\x56\xc4\x8d\xc5\x22\xcc\xb0\xd3\xac\xf9\xcd\x2a\xaf\x0f\xc0" +
+	"\xf9\x39\x94\x8a\x23\xdf\x9f\xdb\x9a\x6c\x0e\xa8\xdf\xe0\x14\x7e\x81\xcd\x42\x31\x96\x88\xf0\x2c\xe4\x23\xa8\x82" +
+	"\x46\xd8\x6d\x28\xb4\x97\xa8\xb4\x14\x4b\xb7\xf1\x88\x3b\x00\x39\xa6\x9c\x8f\x87\x99\x50\x7b\x76\x80\x3c\x8c\xbd" +
+	"\x0e\xbc\x95\xcc\x1b\xb5\xea\x1e\xa3\xd5\xd4\xf0\xd0\xc4\x29\xd5\xab\x89\x83\x63\x3a\x9a\x57\xda\x23\xda\xd4\xb1" +
+	"\xf9\x4d\x63\x1a\xe8\x6a\x4c\xa2\x5e\x48\x0e\xa7\xfd\xd6\xb7\xd6\x3f\x03\x00\x2e\xef\x06\xea\x2e\x0f\x00\x00")
 
 func bindataGmrespetscGMREScBytes() ([]byte, error) {
 	return bindataRead(
@@ -134,10 +143,10 @@ func bindataGmrespetscGMRESc() (*asset, error) {
 
 	info := bindataFileInfo{
 		name: "gmres-petsc/GMRES.c",
-		size: 2826,
+		size: 3886,
 		md5checksum: "",
 		mode: os.FileMode(420),
-		modTime: time.Unix(1546775110, 0),
+		modTime: time.Unix(1792179186, 0),
 	}
 
 	a := &asset{bytes: bytes, info: info}
@@ -146,13 +155,12 @@ func bindataGmrespetscGMRESc() (*asset, error) {
 }
 
 var _bindataGmrespetscMakefile = []byte(
-	"\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x8e\x3f\x4f\x87\x30\x10\x86\x67\xee\x53\xdc\xc0\x0a\xdd\xd9\x14\xab" +
-	"\x36\x80\x36\x85\xc1\x8d\x94\x5a\x62\xe3\xa5\x25\xfc\x71\x21\xfd\xee\xa6\x12\x75\xfd\x4d\x97\x37\xf7\x3c\xef\x9d" +
-	"\xf3\x86\x8e\x77\x8b\xf9\x29\xf9\xd0\xd7\xe3\x83\x50\x91\x91\x9b\xd8\x62\xf7\xcd\x30\x13\xfc\xcc\xbe\xf4\xea\xf4" +
-	"\x44\x76\x83\x5b\xe8\xf5\x48\x24\x68\xa2\x0a\x9f\x3a\xc5\xfb\x32\x20\x9a\x8f\xcf\xb0\xec\x1b\x64\xf9\x59\xb7\xe2" +
-	"\xa5\xe1\x2a\x62\x11\xae\xfd\x3f\xf5\x5b\xdb\xf4\x72\x6c\xc5\x7d\x04\x58\x0f\x5f\xa1\x26\x4a\x62\x27\x05\x7f\xe3" +
-	"\x75\xc4\x92\x5d\x5e\xe1\x66\xcc\x4f\xf1\x28\xef\x86\xe7\x54\x97\xd2\xeb\x95\x20\x03\x43\x56\xfb\xf4\x45\x72\x55" +
-	"\x17\xff\xce\xfc\x4c\xf8\x0e\x00\x00\xff\xff\x8f\x52\x00\xee\xf9\x00\x00\x00")
+	"\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xca\xcc\x4b\xce\x29\x4d\x49\x55\x50\xa9\x0e\x70\x0d\x09\x76\x8e\x77\xf1" +
+	"\x0c\xaa\xd5\xcf\xc9\x4c\xd2\x2f\x48\x2d\x29\x4e\xd6\x4f\xce\xcf\x4b\xd3\x2f\x4b\x2c\xca\x4c\x4c\xca\x49\x2d\xe6" +
+	"\x22\x46\x75\x51\x29\x48\x25\x57\x62\x4e\x8e\x95\x82\xbb\x6f\x90\x6b\xb0\x5e\xbe\x82\x42\x72\x46\x76\x7e\x41\x49" +
+	"\x31\x17\xa7\x4a\xb5\xb3\x8f\xa7\x9f\xb7\x6b\x50\xad\x82\x6e\x3e\x44\x1e\xa1\x0a\x66\xac\x77\x70\x40\xbc\x8f\xa7" +
+	"\x53\x2d\x17\x57\x72\x4e\x6a\x62\x1e\xc8\x28\x90\xce\x20\xdf\x5a\xb8\x5a\x77\xdf\x20\xd7\x60\x2e\xc0\x00\xc6\xf7" +
+	"\x6a\x1b\xbe\x00\x00\x00")
 
 func bindataGmrespetscMakefileBytes() ([]byte, error) {
 	return bindataRead(
@@ -171,10 +179,10 @@ func bindataGmrespetscMakefile() (*asset, error) {
 
 	info := bindataFileInfo{
 		name: "gmres-petsc/makefile",
-		size: 249,
+		size: 190,
 		md5checksum: "",
 		mode: os.FileMode(420),
-		modTime: time.Unix(1522159232, 0),
+		modTime: time.Unix(1792179855, 0),
 	}
 
 	a := &asset{bytes: bytes, info: info}
@@ -5,11 +5,15 @@ static char help[] = "Use GMRES to solve linear systems\n\n";
 
 
 typedef struct{
-    char           ifname[PETSC_MAX_PATH_LEN],ofname[PETSC_MAX_PATH_LEN];
+    char           ifname[PETSC_MAX_PATH_LEN],ofname[PETSC_MAX_PATH_LEN],rfname[PETSC_MAX_PATH_LEN];
 } Parameter;
 
 int main(int argc,char **argv) {
     PetscViewer    ifd,ofd;                   //file viewer
+    FILE           *rfd;                      //report file
+    KSPConvergedReason reason;                //solve outcome
+    PetscInt       its;                       //solve iterations
+    PetscReal      rnorm;                     //solve final residual norm
     Vec            b;                         //RHS and approx solution (sharing memory)
     Mat            A;                         //linear system matrix
     KSP            ksp;                       //linear solver context
@@ -25,13 +29,19 @@ int main(int argc,char **argv) {
 
     ierr = PetscBagSetName(bag,"ParameterBag","contains parameters for script");CHKERRQ(ierr);
     ierr = PetscBagRegisterString(bag,&params->ifname,PETSC_MAX_PATH_LEN,"Ab.ptsc","if","Name of input file file");CHKERRQ(ierr);
-    ierr = PetscBagRegisterString(bag,&params->ofname,PETSC_MAX_PATH_LEN,"sol.matlab","of","Name of output file file");CHKERRQ(ierr);
+    ierr = PetscBagRegisterString(bag,&params->ofname,PETSC_MAX_PATH_LEN,"sol.ptsc","of","Name of output file file");CHKERRQ(ierr);
+    ierr = PetscBagRegisterString(bag,&params->rfname,PETSC_MAX_PATH_LEN,"report.txt","rf","Name of report file file");CHKERRQ(ierr);
 
     // Open input file
     ierr = PetscViewerBinaryOpen(PETSC_COMM_WORLD,params->ifname,FILE_MODE_READ,&ifd);CHKERRQ(ierr);
-    // Open output file
-    ierr = PetscViewerASCIIOpen(PETSC_COMM_WORLD,params->ofname,&ofd); CHKERRQ(ierr);
-    ierr = PetscViewerPushFormat(ofd,PETSC_VIEWER_ASCII_MATLAB); CHKERRQ(ierr);
+    // Open output file, binary without the .info companion file
+    ierr = PetscViewerCreate(PETSC_COMM_WORLD,&ofd);CHKERRQ(ierr);
+    ierr = PetscViewerSetType(ofd,PETSCVIEWERBINARY);CHKERRQ(ierr);
+    ierr = PetscViewerFileSetMode(ofd,FILE_MODE_WRITE);CHKERRQ(ierr);
+    ierr = PetscViewerBinarySkipInfo(ofd);CHKERRQ(ierr);
+    ierr = PetscViewerFileSetName(ofd,params->ofname);CHKERRQ(ierr);
+    // Open report file
+    ierr = PetscFOpen(PETSC_COMM_WORLD,params->rfname,"w",&rfd); CHKERRQ(ierr);
 
     // Load the matrix.
     ierr = MatCreate(PETSC_COMM_WORLD,&A);CHKERRQ(ierr);
@@ -52,14 +62,18 @@ int main(int argc,char **argv) {
     for (ierr = VecLoad(b,ifd); !ierr; ierr = VecLoad(b,ifd)){
         ierr = KSPSolve(ksp,b,b);CHKERRQ(ierr);
         ierr = VecView(b,ofd);CHKERRQ(ierr);
+        // Report convergence reason, iterations and residual norm
+        ierr = KSPGetConvergedReason(ksp,&reason);CHKERRQ(ierr);
+        ierr = KSPGetIterationNumber(ksp,&its);CHKERRQ(ierr);
+        ierr = KSPGetResidualNorm(ksp,&rnorm);CHKERRQ(ierr);
+        ierr = PetscFPrintf(PETSC_COMM_WORLD,rfd,"%d %d %.17g\n",(int)reason,(int)its,(double)rnorm);CHKERRQ(ierr);
     }
     CHKERRQ(ierr == PETSC_ERR_FILE_READ? 0 : ierr);
 
     //Free work space.
     ierr = PetscViewerDestroy(&ifd);CHKERRQ(ierr);
-    ierr = PetscViewerFlush(ofd);CHKERRQ(ierr);
-    ierr = PetscViewerPopFormat(ofd); CHKERRQ(ierr);
     ierr = PetscViewerDestroy(&ofd);CHKERRQ(ierr);
+    ierr = PetscFClose(PETSC_COMM_WORLD,rfd);CHKERRQ(ierr);
     ierr = KSPDestroy(&ksp);CHKERRQ(ierr);
     ierr = VecDestroy(&b);CHKERRQ(ierr);
     ierr = MatDestroy(&A);CHKERRQ(ierr);
@@ -1,4 +1,4 @@
-//Package gmres provides a wrapper for gmres-petsc.
+//Package gmres provides a wrapper for gmres-petsc and an equivalent pure Go solver.
 package gmres
 
 import (
@@ -15,12 +15,29 @@ import (
 
 const solverDir = "gmres-petsc"
 
-//Run executes the gmres command on the given directory with the given context
-func Run(ctx context.Context, infile, outfile, tmpdir string) (err error) {
+//Available reports whether a PETSc installation, required by Run, can be found.
+func Available() bool {
+	dir := os.Getenv("PETSC_DIR")
+	if dir == "" {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lib", "petsc", "conf", "variables")); err != nil {
+		return false
+	}
+	_, err := exec.LookPath("make")
+	return err == nil
+}
+
+//Run executes the gmres command on the given directory with the given context, forwarding the given PETSc options.
+//The solutions are written in outfile and, for each one of them, a line with convergence reason, iterations and residual norm in reportfile.
+//If keep is set, the solver sources restored in tmpdir are left in place, otherwise they are removed.
+//The solver is built with make and then run directly as a single process, not through the MPIEXEC launcher of PETSc,
+//with the options as separate arguments, so that they reach it as they are, without going through make or the shell.
+func Run(ctx context.Context, infile, outfile, reportfile, tmpdir string, options []string, keep bool) (err error) {
 	if err = RestoreAssets(tmpdir, solverDir); err != nil {
 		return errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to convert to restore asset %s", solverDir)
 	}
-	for _, p := range []*string{&infile, &outfile} {
+	for _, p := range []*string{&infile, &outfile, &reportfile} {
 		ap, err := filepath.Abs(*p)
 		if err != nil {
 			return errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to convert to absolute path %s", *p)
@@ -28,17 +45,27 @@ func Run(ctx context.Context, infile, outfile, tmpdir string) (err error) {
 		*p = ap
 	}
 
-	cmd := exec.CommandContext(ctx, "make", "run", "IFPATH="+infile, "OFPATH="+outfile)
+	dir := filepath.Join(tmpdir, solverDir)
+	if !keep {
+		defer os.RemoveAll(dir)
+	}
 
-	var cmdStderr bytes.Buffer
-	cmd.Stderr = &cmdStderr
-	cmd.Dir = filepath.Join(tmpdir, solverDir)
-	defer os.RemoveAll(cmd.Dir)
+	//build solver
+	if err = run(exec.CommandContext(ctx, "make", "all"), dir); err != nil {
+		return
+	}
 
 	//run solver
+	args := append([]string{"-if", infile, "-of", outfile, "-rf", reportfile}, options...)
+	return run(exec.CommandContext(ctx, "./GMRES", args...), dir)
+}
+
+func run(cmd *exec.Cmd, dir string) (err error) {
+	var cmdStderr bytes.Buffer
+	cmd.Stderr = &cmdStderr
+	cmd.Dir = dir
 	if err = cmd.Run(); err != nil {
 		return errors.Wrap(err, "AbsorbingMarkovChain Error: call to external command - PETSc GMRES - failed, with the following error stream:\n"+cmdStderr.String())
 	}
-
 	return
 }
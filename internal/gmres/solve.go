@@ -0,0 +1,269 @@
+package gmres
+
+import (
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// Matrix represents a square sparse matrix in compressed sparse row format:
+// the column indices and the values of the i-th row are Cols[RowPtr[i]:RowPtr[i+1]] and Values[RowPtr[i]:RowPtr[i+1]].
+type Matrix struct {
+	RowPtr []int
+	Cols   []uint32
+	Values []float64
+}
+
+// Size returns the number of rows of the matrix.
+func (A Matrix) Size() int {
+	if len(A.RowPtr) == 0 {
+		return 0
+	}
+	return len(A.RowPtr) - 1
+}
+
+func (A Matrix) mul(x, y []float64) {
+	for i := range y {
+		s := 0.0
+		for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+			s += A.Values[p] * x[A.Cols[p]]
+		}
+		y[i] = s
+	}
+}
+
+func (A Matrix) diagonal() (d []float64, err error) {
+	d = make([]float64, A.Size())
+	for i := range d {
+		for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+			if A.Cols[p] == uint32(i) {
+				d[i] += A.Values[p]
+			}
+		}
+		if d[i] == 0 {
+			return nil, errors.Errorf("AbsorbingMarkovChain Error: zero pivot in row %v.", i)
+		}
+	}
+	return
+}
+
+// Options represents the settings of the pure Go GMRES solver.
+type Options struct {
+	RTol, ATol, DTol float64
+	MaxIterations    int
+	Restart          int
+	PCType           string
+}
+
+func preconditioner(A Matrix, pcType string) (apply func(dst, src []float64), err error) {
+	switch pcType {
+	case "none":
+		return func(dst, src []float64) { copy(dst, src) }, nil
+	case "jacobi", "sor":
+		//handled below
+	default:
+		return nil, errors.Errorf("AbsorbingMarkovChain Error: unsupported preconditioner %v.", pcType)
+	}
+
+	d, err := A.diagonal()
+	if err != nil {
+		return
+	}
+
+	if pcType == "jacobi" {
+		return func(dst, src []float64) {
+			for i, v := range src {
+				dst[i] = v / d[i]
+			}
+		}, nil
+	}
+
+	//symmetric SOR with unit relaxation factor, as PETSc PCSOR default: (D+L)·D^-1·(D+U)
+	return func(dst, src []float64) {
+		for i := range dst { //forward sweep
+			s := src[i]
+			for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+				if j := int(A.Cols[p]); j < i {
+					s -= A.Values[p] * dst[j]
+				}
+			}
+			dst[i] = s / d[i]
+		}
+		for i := len(dst) - 1; i >= 0; i-- { //backward sweep
+			s := 0.0
+			for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+				if j := int(A.Cols[p]); j > i {
+					s += A.Values[p] * dst[j]
+				}
+			}
+			dst[i] -= s / d[i]
+		}
+	}, nil
+}
+
+// Convergence reasons, with the same values of PETSc KSPConvergedReason.
+const (
+	ConvergedRTol     = 2
+	ConvergedATol     = 3
+	DivergedIts       = -3
+	DivergedDTol      = -4
+	DivergedBreakdown = -5
+	DivergedNaNOrInf  = -9
+)
+
+// Stats represents the outcome of a solve: the convergence reason, the number of iterations and the final residual norm.
+type Stats struct {
+	Reason     int
+	Iterations int
+	Residual   float64
+}
+
+// Solve solves A·x = b with the restarted GMRES method, right preconditioned as specified in the options.
+func Solve(ctx context.Context, A Matrix, b []float64, o Options) (x []float64, s Stats, err error) {
+	fail := func(e error) ([]float64, Stats, error) {
+		x, s, err = nil, Stats{}, e
+		return x, s, err
+	}
+
+	n := A.Size()
+	if len(b) != n {
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: right hand side of length %v for a system of size %v.", len(b), n))
+	}
+	pc, err := preconditioner(A, o.PCType)
+	if err != nil {
+		return fail(err)
+	}
+	m := o.Restart
+	if m > n {
+		m = n
+	}
+	if m < 1 {
+		m = 1
+	}
+
+	x = make([]float64, n)
+	bnorm := norm(b)
+	if bnorm == 0 {
+		s.Reason = ConvergedATol
+		return
+	}
+	tol := math.Max(o.RTol*bnorm, o.ATol)
+
+	V := make([][]float64, m+1)
+	for i := range V {
+		V[i] = make([]float64, n)
+	}
+	H := make([][]float64, m+1)
+	for i := range H {
+		H[i] = make([]float64, m)
+	}
+	cs, sn, g, y := make([]float64, m), make([]float64, m), make([]float64, m+1), make([]float64, m)
+	z, w := make([]float64, n), make([]float64, n)
+
+	for breakdown := false; ; {
+		//true residual
+		A.mul(x, w)
+		for i := range w {
+			V[0][i] = b[i] - w[i]
+		}
+		beta := norm(V[0])
+		s.Residual = beta
+		switch {
+		case math.IsNaN(beta) || math.IsInf(beta, 0):
+			s.Reason = DivergedNaNOrInf
+		case beta <= o.ATol:
+			s.Reason = ConvergedATol
+		case beta <= tol:
+			s.Reason = ConvergedRTol
+		case breakdown:
+			s.Reason = DivergedBreakdown
+		case s.Iterations >= o.MaxIterations:
+			s.Reason = DivergedIts
+		case beta > o.DTol*bnorm:
+			s.Reason = DivergedDTol
+		}
+		if s.Reason != 0 {
+			return
+		}
+		for i := range V[0] {
+			V[0][i] /= beta
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = beta
+
+		k := 0
+		for k < m && s.Iterations < o.MaxIterations && !breakdown {
+			if err = ctx.Err(); err != nil {
+				return fail(err)
+			}
+			s.Iterations++
+
+			//Arnoldi step with modified Gram-Schmidt
+			pc(z, V[k])
+			A.mul(z, w)
+			for j := 0; j <= k; j++ {
+				h := dot(w, V[j])
+				H[j][k] = h
+				for i := range w {
+					w[i] -= h * V[j][i]
+				}
+			}
+			hnext := norm(w)
+			breakdown = hnext <= 1e-30*beta
+			if !breakdown {
+				for i := range w {
+					V[k+1][i] = w[i] / hnext
+				}
+			}
+
+			//Givens rotations
+			for j := 0; j < k; j++ {
+				H[j][k], H[j+1][k] = cs[j]*H[j][k]+sn[j]*H[j+1][k], -sn[j]*H[j][k]+cs[j]*H[j+1][k]
+			}
+			r := math.Hypot(H[k][k], hnext)
+			cs[k], sn[k] = H[k][k]/r, hnext/r
+			H[k][k] = r
+			g[k], g[k+1] = cs[k]*g[k], -sn[k]*g[k]
+
+			k++
+			if math.Abs(g[k]) <= tol {
+				break
+			}
+		}
+
+		//update x with the least squares solution in the Krylov space
+		for i := k - 1; i >= 0; i-- {
+			s := g[i]
+			for j := i + 1; j < k; j++ {
+				s -= H[i][j] * y[j]
+			}
+			y[i] = s / H[i][i]
+		}
+		for i := range w {
+			w[i] = 0
+		}
+		for j := 0; j < k; j++ {
+			for i := range w {
+				w[i] += y[j] * V[j][i]
+			}
+		}
+		pc(z, w)
+		for i := range x {
+			x[i] += z[i]
+		}
+	}
+}
+
+func dot(a, b []float64) (s float64) {
+	for i, v := range a {
+		s += v * b[i]
+	}
+	return
+}
+
+func norm(a []float64) float64 {
+	return math.Sqrt(dot(a, a))
+}
@@ -0,0 +1,49 @@
+package gmres
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	//non symmetric, diagonally dominant, 4x4 system
+	A := Matrix{
+		RowPtr: []int{0, 2, 5, 8, 10},
+		Cols:   []uint32{0, 1, 0, 1, 2, 1, 2, 3, 0, 3},
+		Values: []float64{4, -1, -2, 5, -1, -1, 6, -2, -1, 3},
+	}
+	x := []float64{1, -2, 0.5, 3}
+	b := make([]float64, len(x))
+	A.mul(x, b)
+
+	for _, pc := range []string{"none", "jacobi", "sor"} {
+		o := Options{RTol: 1e-8, ATol: 1e-16, DTol: 1e4, MaxIterations: 500, PCType: pc}
+		for _, restart := range []int{1, 2, 30} {
+			o.Restart = restart
+			sol, s, err := Solve(context.Background(), A, b, o)
+			switch {
+			case err != nil:
+				t.Fatal(err)
+			case s.Reason <= 0:
+				t.Errorf("pc %v, restart %v: solve diverged with reason %v", pc, restart, s.Reason)
+			}
+			for i := range x {
+				if math.Abs(sol[i]-x[i]) > 1e-7 {
+					t.Errorf("pc %v, restart %v: x[%v] is %v while is evaluated as %v", pc, restart, i, x[i], sol[i])
+				}
+			}
+		}
+	}
+}
+
+func TestSolveDiverged(t *testing.T) {
+	A := Matrix{RowPtr: []int{0, 2, 4}, Cols: []uint32{0, 1, 0, 1}, Values: []float64{1, 2, 3, 1}}
+	_, s, err := Solve(context.Background(), A, []float64{1, 1}, Options{RTol: 1e-8, DTol: 1e4, MaxIterations: 1, Restart: 1, PCType: "none"})
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case s.Reason != DivergedIts || s.Iterations != 1:
+		t.Errorf("Solve should diverge after 1 iteration, while it ends with reason %v after %v iterations", s.Reason, s.Iterations)
+	}
+}
@@ -0,0 +1,107 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// LocalAbsorptionProbabilities approximates the absorption probabilities of source with a forward push algorithm: the
+// probability mass of the source is pushed along the arcs until the residual mass, not yet absorbed, is at most epsilon.
+// The returned probabilities, keyed by absorbing node, are thus within epsilon in L1 norm from the exact ones.
+// Epsilon must be positive. Only the nodes reached from source are visited and, unlike the other computations, the chain
+// is not validated: its absorbing nodes are trusted as given. If the reached nodes include a class that never gets
+// absorbed, the push stops only when ctx is done.
+func (chain *AbsorbingMarkovChain) LocalAbsorptionProbabilities(ctx context.Context, source uint32, epsilon float64) (probabilities map[uint32]float64, residual float64, err error) {
+	fail := func(e error) (map[uint32]float64, float64, error) {
+		probabilities, residual, err = nil, 0, e
+		return probabilities, residual, err
+	}
+
+	switch {
+	case chain == nil:
+		return fail(errors.New("AbsorbingMarkovChain Error: nil chain"))
+	case !chain.Nodes.Contains(source):
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: %v is not a node.", source))
+	case !(epsilon > 0):
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: %v is not a valid epsilon.", epsilon))
+	}
+
+	probabilities = map[uint32]float64{}
+	if chain.absorbingNodes.Contains(source) {
+		probabilities[source] = 1
+		return
+	}
+
+	weighter := checkedWeighter(chain.Weighter)
+	type arcs struct {
+		to []uint32
+		p  []float64 //transition probabilities, self loop excluded
+	}
+	cache := map[uint32]arcs{}
+	outArcs := func(from uint32) (a arcs, err error) {
+		a, ok := cache[from]
+		if ok {
+			return
+		}
+		total, self := 0.0, 0.0
+		for _, to := range chain.Edges(from) {
+			if !chain.Nodes.Contains(to) {
+				return a, Problem{Kind: DanglingArc, From: from, To: to}
+			}
+			w, err := weighter(from, to)
+			if err != nil {
+				return a, err
+			}
+			total += w
+			if to == from {
+				self += w
+				continue
+			}
+			a.to = append(a.to, to)
+			a.p = append(a.p, w)
+		}
+		if total == self {
+			return a, errors.Errorf("AbsorbingMarkovChain Error: transient node %v can't be left.", from)
+		}
+		for i := range a.p { //the mass looping on from is eventually pushed along the other arcs
+			a.p[i] /= total - self
+		}
+		cache[from] = a
+		return
+	}
+
+	r := map[uint32]float64{source: 1}
+	queue := []uint32{source}
+	residual = 1
+	for residual > epsilon && len(queue) > 0 {
+		if err = ctx.Err(); err != nil {
+			return fail(err)
+		}
+		from := queue[0]
+		queue = queue[1:]
+		mass := r[from]
+		delete(r, from)
+
+		a, err := outArcs(from)
+		if err != nil {
+			return fail(err)
+		}
+		for i, to := range a.to {
+			m := mass * a.p[i]
+			if chain.absorbingNodes.Contains(to) {
+				probabilities[to] += m
+				residual -= m
+				continue
+			}
+			if _, ok := r[to]; !ok {
+				queue = append(queue, to)
+			}
+			r[to] += m
+		}
+	}
+	if residual < 0 { //rounding errors
+		residual = 0
+	}
+	return
+}
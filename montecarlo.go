@@ -0,0 +1,187 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// MonteCarloOptions represents the settings of EstimateAbsorptionProbabilities, zero values stand for the defaults.
+type MonteCarloOptions struct {
+	Walks      int             // random walks from each source node, by default 1000
+	MaxSteps   int             // maximum steps of each walk, truncated walks are not absorbed; by default unlimited
+	Sources    *roaring.Bitmap // transient nodes from which walks start, by default all of them
+	Confidence float64         // confidence level of the intervals, by default 0.95
+	Workers    int             // number of goroutines, by default runtime.NumCPU(); see EstimateAbsorptionProbabilities
+}
+
+// Estimate represents an estimated probability along with its confidence interval [Low, High].
+type Estimate struct {
+	P, Low, High float64
+}
+
+// EstimateAbsorptionProbabilities estimates absorption probabilities for the current absorbing markov chain, counting where
+// random walks from the source nodes are absorbed. If the chain has a source of randomness set by WithRand, the estimates
+// are reproducible, for any number of workers. Confidence intervals are Wilson score intervals.
+// Walks run in parallel, so the Edges and Weighter callbacks of the chain are called concurrently: they must be safe for
+// concurrent use, otherwise Workers must be set to 1. Walks absorbed in unassigned sinks, see AbsorbUnreachable, are
+// counted for no absorbing node.
+func (chain *AbsorbingMarkovChain) EstimateAbsorptionProbabilities(ctx context.Context, o MonteCarloOptions) (estimator func(from, to uint32) (estimate Estimate, err error), err error) {
+	fail := func(e error) (func(from, to uint32) (Estimate, error), error) {
+		estimator, err = nil, e
+		return estimator, err
+	}
+
+	if chain, err = chain.checkRequirements(); err != nil {
+		return fail(err)
+	}
+
+	transientNodes := roaring.AndNot(chain.Nodes, chain.absorbingNodes)
+	sources := o.Sources
+	switch {
+	case sources == nil:
+		sources = transientNodes
+	case !roaring.AndNot(sources, transientNodes).IsEmpty():
+		v, _ := roaring.AndNot(sources, transientNodes).Select(0)
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: %v is not a transient node.", v))
+	}
+	if o.Walks < 1 {
+		o.Walks = 1000
+	}
+	if o.Confidence <= 0 || o.Confidence >= 1 {
+		o.Confidence = 0.95
+	}
+	if o.Workers < 1 {
+		o.Workers = runtime.NumCPU()
+	}
+
+	//a seed for each source, so that estimates don't depend on scheduling
+	int63 := rand.Int63
+	if chain.rand != nil {
+		int63 = chain.rand.Int63
+	}
+	sourceIDs := sources.ToArray()
+	seeds := make([]int64, len(sourceIDs))
+	for i := range seeds {
+		seeds[i] = int63()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	setErr := func(e error) {
+		once.Do(func() {
+			err = e
+			cancel()
+		})
+	}
+
+	counts := make([][]NodeWeight, len(sourceIDs)) //absorbing nodes, sorted by ID, with absorbed walks count
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c, e := chain.walks(ctx, sourceIDs[i], rand.New(rand.NewSource(seeds[i])), o)
+				if e != nil {
+					setErr(e)
+					continue
+				}
+				counts[i] = c
+			}
+		}()
+	}
+	for i := range sourceIDs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return fail(err)
+	}
+	if err = ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	absorbingNodes, walks := chain.assignableNodes(), float64(o.Walks) //without unassigned sinks
+	z := math.Sqrt2 * math.Erfinv(o.Confidence)
+	return func(from, to uint32) (estimate Estimate, err error) {
+		i := sort.Search(len(sourceIDs), func(i int) bool { return sourceIDs[i] >= from })
+		switch {
+		case i == len(sourceIDs) || sourceIDs[i] != from:
+			return estimate, errors.Errorf("AbsorbingMarkovChain Error: %v is not a source node.", from)
+		case !absorbingNodes.Contains(to):
+			return estimate, errors.Errorf("AbsorbingMarkovChain Error: %v is not an absorbing node.", to)
+		}
+		c := counts[i]
+		count := 0.0
+		if j := sort.Search(len(c), func(j int) bool { return c[j].Node >= to }); j < len(c) && c[j].Node == to {
+			count = c[j].Weight
+		}
+		return wilsonInterval(count, walks, z), nil
+	}, nil
+}
+
+// walks runs the random walks from source, returning how many of them are absorbed in each absorbing node.
+func (chain *AbsorbingMarkovChain) walks(ctx context.Context, source uint32, r *rand.Rand, o MonteCarloOptions) (counts []NodeWeight, err error) {
+	absorbed := map[uint32]float64{}
+	var weights []float64
+	for w := 0; w < o.Walks; w++ {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		node := source
+		for steps := 1; o.MaxSteps < 1 || steps <= o.MaxSteps; steps++ {
+			if steps%ctxCheckSteps == 0 {
+				if err = ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			if chain.absorbingNodes.Contains(node) {
+				absorbed[node]++
+				break
+			}
+			to := chain.Edges(node)
+			weights = weights[:0]
+			total := 0.0
+			for _, v := range to {
+				weight, err := chain.Weighter(node, v)
+				if err != nil {
+					return nil, err
+				}
+				total += weight
+				weights = append(weights, total)
+			}
+			x := r.Float64() * total
+			node = to[sort.SearchFloat64s(weights, x)%len(to)]
+		}
+	}
+
+	for node, count := range absorbed {
+		counts = append(counts, NodeWeight{node, count})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Node < counts[j].Node })
+	return
+}
+
+// ctxCheckSteps is the number of steps of a walk between checks of the context.
+const ctxCheckSteps = 1024
+
+func wilsonInterval(successes, trials, z float64) Estimate {
+	p := successes / trials
+	z2 := z * z
+	center := (p + z2/(2*trials)) / (1 + z2/trials)
+	halfWidth := z / (1 + z2/trials) * math.Sqrt(p*(1-p)/trials+z2/(4*trials*trials))
+	return Estimate{p, math.Max(0, center-halfWidth), math.Min(1, center+halfWidth)}
+}
@@ -0,0 +1,106 @@
+package absorbingmarkovchain
+
+import (
+	"math/rand"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+// Option represents a configuration option of an AbsorbingMarkovChain.
+type Option func(chain *AbsorbingMarkovChain)
+
+// WithSolver sets the solver used for the linear systems of the chain, by default DefaultSolver is used.
+func WithSolver(solver Solver) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.solver = solver
+	}
+}
+
+// WithSolverOptions sets the settings passed to the solver of the chain.
+func WithSolverOptions(o SolverOptions) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.solverOptions = o
+	}
+}
+
+// TieBreaking represents the policy used to choose among absorbing nodes with the same highest absorption probability.
+type TieBreaking int
+
+// Tie breaking policies.
+const (
+	TieRandom    TieBreaking = iota // a random absorbing node, see WithRand; the default
+	TieLowestID                     // the absorbing node with the lowest ID
+	TieHighestID                    // the absorbing node with the highest ID
+	TieAmbiguous                    // no absorbing node, the transient node is reported as ambiguous
+)
+
+// WithTieBreaking sets the tie breaking policy of the chain.
+func WithTieBreaking(p TieBreaking) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.tieBreaking = p
+	}
+}
+
+// WithRand sets the source of randomness of the chain, by default the global source of math/rand is used.
+// A seeded source makes results reproducible.
+func WithRand(r *rand.Rand) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.rand = r
+	}
+}
+
+// WithClosedClasses sets whether NewInferred makes absorbing the nodes of closed classes, so that the probability of
+// being absorbed in a class is the sum of the absorption probabilities of its nodes.
+func WithClosedClasses(closedClasses bool) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.closedClasses = closedClasses
+	}
+}
+
+// UnreachablePolicy represents how transient nodes that can't reach any absorbing node are handled.
+type UnreachablePolicy int
+
+// Unreachable nodes policies.
+const (
+	FailUnreachable   UnreachablePolicy = iota // the computation fails; the default
+	DropUnreachable                            // the nodes are removed from the chain, along with the arcs towards them
+	AbsorbUnreachable                          // the nodes become absorbing, as unassigned sinks that are never targets
+)
+
+// WithUnreachablePolicy sets how the chain handles transient nodes that can't reach any absorbing node.
+// Dropped or unassigned nodes are reported in SolveReport. Unassigned sinks are never absorbing nodes in estimates, and
+// make ExpectedStepsToAbsorption, AbsorptionTimeVariance and ExpectedVisits fail, as the chain is then not absorbing.
+func WithUnreachablePolicy(p UnreachablePolicy) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.unreachablePolicy = p
+	}
+}
+
+// WithTargets restricts the computation of absorption probabilities to the given absorbing nodes, solving only their
+// right hand sides: assignments and rankings are then among the targets only. By default all absorbing nodes are targets;
+// an empty set of targets makes the computation fail.
+func WithTargets(targets *roaring.Bitmap) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.targets = targets
+	}
+}
+
+// WithPruning stores absorption probabilities sparsely: only the entries above epsilon and, if k is positive, the k
+// highest ones of each transient node are retained, the others read as 0. Right hand sides are then solved in batches,
+// so that the dense solution of all of them is never held in memory. The dropped mass is reported in SolveReport.
+func WithPruning(epsilon float64, k int) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.pruning = pruning{epsilon: epsilon, k: k}
+	}
+}
+
+// WithArtifactsDir keeps the solver files in dir, created if needed, instead of a temporary directory removed after
+// solving: along with the system description, the Ab.ptsc file of each batch of right hand sides is kept in its own
+// subdirectory, where PETScSolver, if it's the solver of the chain, also leaves its outputs and the solver sources.
+// The directory can be solved again with Replay. It only applies to absorption probabilities: ExpectedStepsToAbsorption,
+// AbsorptionTimeVariance and ExpectedVisits fail if it's set.
+func WithArtifactsDir(dir string) Option {
+	return func(chain *AbsorbingMarkovChain) {
+		chain.artifactsDir = dir
+	}
+}
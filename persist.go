@@ -0,0 +1,323 @@
+package absorbingmarkovchain
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// resultsMagic and resultsVersion identify the binary format of Results.
+const (
+	resultsMagic   uint32 = 0x414d4352 //"AMCR"
+	resultsVersion uint32 = 1
+)
+
+const (
+	denseStorage uint8 = iota
+	sparseStorage
+)
+
+// systemHash hashes the linear system (I-Q)·X = R, along with the original IDs of its transient and absorbing nodes.
+func systemHash(A *SparseMatrix, R [][]implicitWeightedEdge, ttn, tan translator, nA int) uint64 {
+	h := &chunkedHash{Hash64: fnv.New64a(), buf: make([]byte, 0, 4096)}
+	for _, t := range []struct {
+		t translator
+		n int
+	}{{ttn, A.Size()}, {tan, nA}} {
+		for i := 0; i < t.n; i++ {
+			oldID, _ := t.t.ToOld(uint32(i))
+			h.putUint32(oldID)
+		}
+	}
+	for _, p := range A.RowPtr {
+		h.putUint64(uint64(p))
+	}
+	for _, c := range A.Cols {
+		h.putUint32(c)
+	}
+	for _, v := range A.Values {
+		h.putUint64(math.Float64bits(v))
+	}
+	for _, r := range R {
+		h.putUint32(uint32(len(r)))
+		for _, e := range r {
+			h.putUint32(e.to)
+			h.putUint64(math.Float64bits(e.w))
+		}
+	}
+	return h.sum64()
+}
+
+// chunkedHash feeds a hash with big endian values through a reused buffer, without allocations.
+type chunkedHash struct {
+	hash.Hash64
+	buf []byte
+}
+
+func (h *chunkedHash) reserve(n int) []byte {
+	if len(h.buf)+n > cap(h.buf) {
+		h.Write(h.buf) //hash writes never fail
+		h.buf = h.buf[:0]
+	}
+	h.buf = h.buf[:len(h.buf)+n]
+	return h.buf[len(h.buf)-n:]
+}
+
+func (h *chunkedHash) putUint32(v uint32) {
+	binary.BigEndian.PutUint32(h.reserve(4), v)
+}
+
+func (h *chunkedHash) putUint64(v uint64) {
+	binary.BigEndian.PutUint64(h.reserve(8), v)
+}
+
+func (h *chunkedHash) sum64() uint64 {
+	h.Write(h.buf)
+	h.buf = h.buf[:0]
+	return h.Sum64()
+}
+
+// WriteTo writes the results to w in a compact, versioned binary format, readable by ReadResults.
+func (r *Results) WriteTo(w io.Writer) (n int64, err error) {
+	cw := &countingWriter{w: w}
+	bw := newBinaryWriter(cw)
+
+	bw.write(resultsMagic, resultsVersion)
+
+	m, o := r.Metadata, r.Metadata.SolverOptions
+	bw.write(o.RTol, o.ATol, o.DTol, int64(o.MaxIterations), int64(o.Restart))
+	bw.writeString(o.KSPType)
+	bw.writeString(o.PCType)
+	bw.write(uint32(len(o.PETScOptions)))
+	for _, s := range o.PETScOptions {
+		bw.writeString(s)
+	}
+	bw.write(m.Nodes, m.AbsorbingNodes, m.SystemHash)
+
+	bw.write(r.Report.DroppedMass, uint32(len(r.Report.Stats)))
+	for _, node := range sortedKeys(r.Report.Stats) {
+		s := r.Report.Stats[node]
+		bw.write(node, int32(s.Reason), int64(s.Iterations), s.Residual)
+	}
+	bw.writeBitmap(r.Report.Unreachable)
+	bw.writeBitmap(r.absorbingNodes)
+
+	p := r.probabilities
+	bw.writeTranslator(r.ttn, p.nT)
+	bw.writeTranslator(r.tan, p.nA)
+	switch {
+	case p.dense != nil:
+		bw.write(denseStorage)
+		for _, x := range p.dense {
+			bw.write(x)
+		}
+	default:
+		bw.write(sparseStorage)
+		for _, row := range p.sparse {
+			bw.write(uint32(len(row)))
+			for _, nw := range row {
+				bw.write(nw.Node, nw.Weight)
+			}
+		}
+	}
+
+	if err = bw.flush(); err != nil {
+		err = errors.Wrap(err, "AbsorbingMarkovChain Error: error while writing results.")
+	}
+	return cw.n, err
+}
+
+// ReadResults reads results written by Results.WriteTo, without solving anything.
+func ReadResults(r io.Reader) (results *Results, err error) {
+	br := newBinaryReader(r)
+
+	var magic, version uint32
+	br.read(&magic, &version)
+	switch {
+	case br.err != nil:
+		return nil, errors.Wrap(br.err, "AbsorbingMarkovChain Error: error while reading results.")
+	case magic != resultsMagic:
+		return nil, errors.New("AbsorbingMarkovChain Error: not a results file.")
+	case version != resultsVersion:
+		return nil, errors.Errorf("AbsorbingMarkovChain Error: unsupported results version %v.", version)
+	}
+
+	results = &Results{}
+	m, o := &results.Metadata, &results.Metadata.SolverOptions
+	var maxIterations, restart int64
+	br.read(&o.RTol, &o.ATol, &o.DTol, &maxIterations, &restart)
+	o.MaxIterations, o.Restart = int(maxIterations), int(restart)
+	o.KSPType = br.readString()
+	o.PCType = br.readString()
+	for i, l := 0, br.readLen(); i < l && br.err == nil; i++ {
+		o.PETScOptions = append(o.PETScOptions, br.readString())
+	}
+	br.read(&m.Nodes, &m.AbsorbingNodes, &m.SystemHash)
+
+	br.read(&results.Report.DroppedMass)
+	l := br.readLen()
+	results.Report.Stats = make(map[uint32]SolveStats, l)
+	for i := 0; i < l && br.err == nil; i++ {
+		var node uint32
+		var reason int32
+		var iterations int64
+		var s SolveStats
+		br.read(&node, &reason, &iterations, &s.Residual)
+		s.Reason, s.Iterations = ConvergedReason(reason), int(iterations)
+		results.Report.Stats[node] = s
+	}
+	results.Report.Unreachable = roaring.BitmapOf(br.readUint32s()...)
+	results.absorbingNodes = roaring.BitmapOf(br.readUint32s()...)
+
+	ttn, tan := br.readUint32s(), br.readUint32s()
+	results.ttn, results.tan = myTranslator(ttn), myTranslator(tan)
+	p := &results.probabilities
+	p.nT, p.nA = len(ttn), len(tan)
+	var storage uint8
+	br.read(&storage)
+	switch {
+	case br.err != nil:
+		//handled below
+	case storage == denseStorage:
+		p.dense = make([][]float64, p.nA)
+		for a := range p.dense {
+			p.dense[a] = make([]float64, p.nT)
+			br.read(p.dense[a])
+		}
+	case storage == sparseStorage:
+		p.sparse = make([][]NodeWeight, p.nT)
+		for t := range p.sparse {
+			l := br.readLen()
+			for i := 0; i < l && br.err == nil; i++ {
+				var nw NodeWeight
+				br.read(&nw.Node, &nw.Weight)
+				p.sparse[t] = append(p.sparse[t], nw)
+			}
+		}
+	default:
+		br.err = errors.Errorf("unknown storage %v", storage)
+	}
+
+	if br.err != nil {
+		return nil, errors.Wrap(br.err, "AbsorbingMarkovChain Error: error while reading results.")
+	}
+	return
+}
+
+func sortedKeys(m map[uint32]SolveStats) []uint32 {
+	b := roaring.NewBitmap()
+	for k := range m {
+		b.Add(k)
+	}
+	return b.ToArray()
+}
+
+// binaryWriter writes big endian values, keeping the first error.
+type binaryWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newBinaryWriter(w io.Writer) *binaryWriter {
+	return &binaryWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *binaryWriter) write(vv ...interface{}) {
+	for _, v := range vv {
+		if w.err != nil {
+			return
+		}
+		w.err = binary.Write(w.w, binary.BigEndian, v)
+	}
+}
+
+func (w *binaryWriter) writeString(s string) {
+	w.write(uint32(len(s)), []byte(s))
+}
+
+func (w *binaryWriter) writeBitmap(b *roaring.Bitmap) {
+	if b == nil {
+		b = roaring.NewBitmap()
+	}
+	w.write(uint32(b.GetCardinality()), b.ToArray())
+}
+
+func (w *binaryWriter) writeTranslator(t translator, size int) {
+	w.write(uint32(size))
+	for i := 0; i < size && w.err == nil; i++ {
+		var oldID uint32
+		if oldID, w.err = t.ToOld(uint32(i)); w.err == nil {
+			w.write(oldID)
+		}
+	}
+}
+
+func (w *binaryWriter) flush() error {
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	return w.err
+}
+
+// binaryReader reads big endian values, keeping the first error.
+type binaryReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func newBinaryReader(r io.Reader) *binaryReader {
+	return &binaryReader{r: bufio.NewReader(r)}
+}
+
+func (r *binaryReader) read(vv ...interface{}) {
+	for _, v := range vv {
+		if r.err != nil {
+			return
+		}
+		r.err = binary.Read(r.r, binary.BigEndian, v)
+	}
+}
+
+func (r *binaryReader) readLen() int {
+	var l uint32
+	r.read(&l)
+	return int(l)
+}
+
+func (r *binaryReader) readString() string {
+	l := r.readLen()
+	if r.err != nil {
+		return ""
+	}
+	b := make([]byte, l)
+	_, r.err = io.ReadFull(r.r, b)
+	return string(b)
+}
+
+func (r *binaryReader) readUint32s() []uint32 {
+	l := r.readLen()
+	v := []uint32{}
+	for i := 0; i < l && r.err == nil; i++ {
+		var id uint32
+		r.read(&id)
+		v = append(v, id)
+	}
+	return v
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = w.w.Write(p)
+	w.n += int64(n)
+	return
+}
@@ -1,41 +1,52 @@
 package absorbingmarkovchain
 
 import (
-	"encoding/json"
+	"bufio"
+	"fmt"
 	"io"
 	"os"
 
+	"github.com/ebonetti/absorbingmarkovchain/petscbin"
 	"github.com/pkg/errors"
 )
 
-func petsc2Assignments(ttn, tan translator, filepath string) (fuzzyAssignments [][]float64, err error) {
-	fail := func(e error) ([][]float64, error) {
-		fuzzyAssignments, err = nil, e
-		return fuzzyAssignments, err
+func petsc2Assignments(filepath string) (fuzzyAssignments [][]float64, err error) {
+	solutions, err := os.Open(filepath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "AbsorbingMarkovChain Error: error while opening file at %v.", filepath)
 	}
+	defer solutions.Close()
 
-	solutions, err := os.Open(filepath)
+	if fuzzyAssignments, err = petscbin.ReadVecs(bufio.NewReader(solutions)); err != nil {
+		return nil, errors.Wrapf(err, "AbsorbingMarkovChain Error: error while decoding file at %v.", filepath)
+	}
+
+	return
+}
+
+func petsc2Stats(filepath string) (stats []SolveStats, err error) {
+	fail := func(e error) ([]SolveStats, error) {
+		stats, err = nil, e
+		return stats, err
+	}
+
+	report, err := os.Open(filepath)
 	if err != nil {
 		return fail(errors.Wrapf(err, "AbsorbingMarkovChain Error: error while opening file at %v.", filepath))
 	}
-	defer solutions.Close()
+	defer report.Close()
 
-	d := json.NewDecoder(newMatlab2Json(solutions))
-	fa := []float64{}
-loop:
+	r := bufio.NewReader(report)
 	for {
-		fa = fa[:0]
-		err = d.Decode(&fa)
+		var s SolveStats
+		_, err = fmt.Fscanln(r, &s.Reason, &s.Iterations, &s.Residual)
 		switch err {
 		case nil:
-			fuzzyAssignments = append(fuzzyAssignments, append([]float64{}, fa...))
+			stats = append(stats, s)
 		case io.EOF:
-			err = nil
-			break loop
+			return stats, nil
 		default:
 			return fail(errors.Wrapf(err, "AbsorbingMarkovChain Error: error while decoding file at %v.", filepath))
 		}
 	}
-
-	return
 }
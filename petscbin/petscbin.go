@@ -0,0 +1,145 @@
+// Package petscbin reads and writes PETSc binary files of sparse AIJ matrices and vectors, as MatView and VecView
+// with a binary viewer, with 32 bit indices and double precision scalars.
+package petscbin
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Class identifiers that prefix each object in PETSc binary files.
+const (
+	MatFileClassID int32 = 1211216
+	VecFileClassID int32 = 1211214
+)
+
+// Mat represents a sparse M×N matrix in compressed sparse row format: the column indices and the values of the i-th row
+// are Cols[RowPtr[i]:RowPtr[i+1]] and Values[RowPtr[i]:RowPtr[i+1]].
+type Mat struct {
+	M, N   int
+	RowPtr []int
+	Cols   []uint32
+	Values []float64
+}
+
+// WriteMat writes the matrix A to w in PETSc binary format.
+func WriteMat(w io.Writer, A Mat) (err error) {
+	if len(A.RowPtr) != A.M+1 || len(A.Cols) != len(A.Values) || A.RowPtr[A.M] != len(A.Cols) {
+		return errors.New("PETSc binary Error: inconsistent matrix.")
+	}
+	rowEntries := make([]int32, A.M)
+	for i := range rowEntries {
+		rowEntries[i] = int32(A.RowPtr[i+1] - A.RowPtr[i])
+	}
+
+	/*
+		MAT_FILE_CLASSID //matrix file identifier
+		M,          //number of rows
+		N,          //number of columns
+		entries,    //total number of nonzeros
+		rowEntries, //number nonzeros in each row
+		indices,    //column indices of all nonzeros
+		values,     //values of all nonzeros
+	*/
+	for _, v := range []interface{}{MatFileClassID, int32(A.M), int32(A.N), int32(len(A.Cols)), rowEntries, A.Cols, A.Values} {
+		if err = binary.Write(w, binary.BigEndian, v); err != nil {
+			return errors.Wrap(err, "PETSc binary Error: error while writing a matrix.")
+		}
+	}
+	return
+}
+
+// WriteVec writes the vector v to w in PETSc binary format.
+func WriteVec(w io.Writer, v []float64) (err error) {
+	/*
+		VEC_FILE_CLASSID, //vector file identifier
+		n,                //number of rows
+		v,                //values of all entries
+	*/
+	for _, v := range []interface{}{VecFileClassID, int32(len(v)), v} {
+		if err = binary.Write(w, binary.BigEndian, v); err != nil {
+			return errors.Wrap(err, "PETSc binary Error: error while writing a vector.")
+		}
+	}
+	return
+}
+
+// ReadMat reads a matrix in PETSc binary format from r.
+func ReadMat(r io.Reader) (A Mat, err error) {
+	fail := func(e error) (Mat, error) {
+		A, err = Mat{}, errors.Wrap(e, "PETSc binary Error: error while reading a matrix.")
+		return A, err
+	}
+
+	var header [4]int32
+	if err = binary.Read(r, binary.BigEndian, header[:]); err != nil {
+		return fail(unexpectedEOF(err))
+	}
+	classID, m, n, entries := header[0], header[1], header[2], header[3]
+	if classID != MatFileClassID || m < 0 || n < 0 || entries < 0 {
+		return fail(errors.Errorf("not a matrix, class id %v", classID))
+	}
+
+	rowEntries := make([]int32, m)
+	A = Mat{M: int(m), N: int(n), RowPtr: make([]int, 1, m+1), Cols: make([]uint32, entries), Values: make([]float64, entries)}
+	for _, v := range []interface{}{rowEntries, A.Cols, A.Values} {
+		if err = binary.Read(r, binary.BigEndian, v); err != nil {
+			return fail(unexpectedEOF(err))
+		}
+	}
+	for _, e := range rowEntries {
+		A.RowPtr = append(A.RowPtr, A.RowPtr[len(A.RowPtr)-1]+int(e))
+	}
+	if A.RowPtr[A.M] != len(A.Cols) {
+		return fail(errors.New("row lengths don't match the number of nonzeros"))
+	}
+	for _, c := range A.Cols {
+		if int(c) >= A.N {
+			return fail(errors.Errorf("column index %v out of range", c))
+		}
+	}
+
+	return
+}
+
+// ReadVec reads a vector in PETSc binary format from r. It returns io.EOF if r is at its end.
+func ReadVec(r io.Reader) (v []float64, err error) {
+	var header [2]int32
+	switch err = binary.Read(r, binary.BigEndian, header[:]); {
+	case err == io.EOF:
+		return nil, err
+	case err != nil:
+		return nil, errors.Wrap(err, "PETSc binary Error: error while reading a vector.")
+	case header[0] != VecFileClassID || header[1] < 0:
+		return nil, errors.Errorf("PETSc binary Error: not a vector, class id %v.", header[0])
+	}
+
+	v = make([]float64, header[1])
+	if err = binary.Read(r, binary.BigEndian, v); err != nil {
+		return nil, errors.Wrap(unexpectedEOF(err), "PETSc binary Error: error while reading a vector.")
+	}
+	return
+}
+
+// ReadVecs reads the vectors in PETSc binary format from r, concatenated one after another until its end.
+func ReadVecs(r io.Reader) (vv [][]float64, err error) {
+	for {
+		v, err := ReadVec(r)
+		switch {
+		case err == io.EOF:
+			return vv, nil
+		case err != nil:
+			return nil, err
+		}
+		vv = append(vv, v)
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
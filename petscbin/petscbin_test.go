@@ -0,0 +1,53 @@
+package petscbin
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	A := Mat{M: 3, N: 4, RowPtr: []int{0, 2, 2, 4}, Cols: []uint32{0, 3, 1, 2}, Values: []float64{1, -0.5, math.Pi, 1e-300}}
+	vv := [][]float64{{1, 2, 3}, {}, {math.Inf(1), -0.1, 0}}
+
+	var buf bytes.Buffer
+	if err := WriteMat(&buf, A); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vv {
+		if err := WriteVec(&buf, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := append([]byte{}, buf.Bytes()...)
+
+	r := bytes.NewReader(data)
+	B, err := ReadMat(r)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case !reflect.DeepEqual(A, B):
+		t.Errorf("Read matrix %v differs from written one %v", B, A)
+	}
+	ww, err := ReadVecs(r)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case !reflect.DeepEqual(vv, ww):
+		t.Errorf("Read vectors %v differ from written ones %v", ww, vv)
+	}
+
+	if _, err := ReadMat(bytes.NewReader(data[:len(data)/2])); err == nil {
+		t.Error("Reading a truncated matrix should fail.")
+	}
+	if _, err := ReadVecs(bytes.NewReader(data)); err == nil {
+		t.Error("Reading a matrix as vectors should fail.")
+	}
+	if vv, err := ReadVecs(bytes.NewReader(nil)); err != nil || len(vv) != 0 {
+		t.Errorf("Reading no vectors returned %v, error %v", vv, err)
+	}
+	if err := WriteMat(&buf, Mat{M: 2, N: 2, RowPtr: []int{0, 1}}); err == nil {
+		t.Error("Writing an inconsistent matrix should fail.")
+	}
+}
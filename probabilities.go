@@ -0,0 +1,103 @@
+package absorbingmarkovchain
+
+import (
+	"sort"
+)
+
+// probabilities stores the absorption probabilities of the transient nodes by new IDs: densely as [a][t] or, when
+// pruned, sparsely as the retained entries of each transient node, sorted by absorbing node.
+type probabilities struct {
+	dense  [][]float64    //[a][t]
+	sparse [][]NodeWeight //[t]
+	nA, nT int
+}
+
+// at returns the probability of absorption of the transient node t into the absorbing node a, 0 if pruned or
+// if no absorbing node was solved.
+func (p probabilities) at(a, t int) float64 {
+	switch {
+	case p.dense != nil:
+		return p.dense[a][t]
+	case p.sparse == nil:
+		return 0
+	}
+	row := p.sparse[t]
+	i := sort.Search(len(row), func(i int) bool { return row[i].Node >= uint32(a) })
+	if i < len(row) && row[i].Node == uint32(a) {
+		return row[i].Weight
+	}
+	return 0
+}
+
+// each calls f for the entries of the transient node t by increasing absorbing node: all of them if dense,
+// the retained ones if pruned, none if no absorbing node was solved.
+func (p probabilities) each(t int, f func(a int, w float64)) {
+	switch {
+	case p.dense != nil:
+		for a := range p.dense {
+			f(a, p.dense[a][t])
+		}
+	case p.sparse != nil:
+		for _, nw := range p.sparse[t] {
+			f(int(nw.Node), nw.Weight)
+		}
+	}
+}
+
+// pruning represents the thresholds of sparse storage: entries not above epsilon are dropped and,
+// if k is positive, only the k highest entries of each transient node are retained.
+type pruning struct {
+	epsilon float64
+	k       int
+	batch   int //right hand sides solved together, pruningBatch if not positive
+}
+
+func (pr pruning) enabled() bool {
+	return pr.epsilon > 0 || pr.k > 0
+}
+
+// batchSize returns how many of the n right hand sides are built and solved together: all of them in a single
+// solver call if pruning is disabled, as their dense solutions are all stored anyway.
+func (pr pruning) batchSize(n int) int {
+	batch := pr.batch
+	if batch <= 0 {
+		batch = pruningBatch
+	}
+	if !pr.enabled() || batch > n {
+		batch = n
+	}
+	return batch
+}
+
+// pruningBatch is the number of right hand sides solved together when probabilities are pruned.
+const pruningBatch = 256
+
+// prune adds to the sparse storage the solutions X of the absorbing nodes from offset on, returning the dropped mass.
+func (p *probabilities) prune(X [][]float64, offset int, pr pruning) (dropped float64) {
+	if p.sparse == nil {
+		p.sparse = make([][]NodeWeight, p.nT)
+	}
+	p.nA = offset + len(X)
+	for t := range p.sparse {
+		row := p.sparse[t]
+		for a, x := range X {
+			switch w := x[t]; {
+			case w > pr.epsilon:
+				row = append(row, NodeWeight{uint32(offset + a), w})
+			default:
+				dropped += w
+			}
+		}
+		if pr.k > 0 && len(row) > pr.k {
+			byWeight := append([]NodeWeight(nil), row...)
+			sort.SliceStable(byWeight, func(i, j int) bool { return byWeight[i].Weight > byWeight[j].Weight })
+			for _, nw := range byWeight[pr.k:] {
+				dropped += nw.Weight
+			}
+			row = byWeight[:pr.k]
+			sort.Slice(row, func(i, j int) bool { return row[i].Node < row[j].Node })
+		}
+		p.sparse[t] = row
+	}
+	return
+}
@@ -0,0 +1,86 @@
+package absorbingmarkovchain
+
+import (
+	"fmt"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+// ConvergedReason represents the reason why a solve stopped, with the same values of PETSc KSPConvergedReason:
+// positive values mean convergence and negative values divergence.
+type ConvergedReason int
+
+// Convergence reasons.
+const (
+	ConvergedRTol           ConvergedReason = 2
+	ConvergedATol           ConvergedReason = 3
+	ConvergedIts            ConvergedReason = 4
+	ConvergedHappyBreakdown ConvergedReason = 7
+	DivergedNull            ConvergedReason = -2
+	DivergedIts             ConvergedReason = -3
+	DivergedDTol            ConvergedReason = -4
+	DivergedBreakdown       ConvergedReason = -5
+	DivergedNaNOrInf        ConvergedReason = -9
+	DivergedPCFailed        ConvergedReason = -11
+)
+
+var convergedReasonNames = map[ConvergedReason]string{
+	ConvergedRTol:           "CONVERGED_RTOL",
+	ConvergedATol:           "CONVERGED_ATOL",
+	ConvergedIts:            "CONVERGED_ITS",
+	ConvergedHappyBreakdown: "CONVERGED_HAPPY_BREAKDOWN",
+	DivergedNull:            "DIVERGED_NULL",
+	DivergedIts:             "DIVERGED_ITS",
+	DivergedDTol:            "DIVERGED_DTOL",
+	DivergedBreakdown:       "DIVERGED_BREAKDOWN",
+	DivergedNaNOrInf:        "DIVERGED_NANORINF",
+	DivergedPCFailed:        "DIVERGED_PC_FAILED",
+}
+
+func (r ConvergedReason) String() string {
+	if name, ok := convergedReasonNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("ConvergedReason(%d)", int(r))
+}
+
+// Converged reports whether the reason stands for a converged solve.
+func (r ConvergedReason) Converged() bool {
+	return r > 0
+}
+
+// SolveStats represents the outcome of the solve of a single right hand side.
+type SolveStats struct {
+	Reason     ConvergedReason
+	Iterations int
+	Residual   float64 // final residual norm, as computed by the solver
+}
+
+// SolveReport represents the outcome of the solves of an absorbing markov chain.
+type SolveReport struct {
+	Stats        map[uint32]SolveStats // solve outcome, for each absorbing node
+	Unreachable  *roaring.Bitmap       // transient nodes that can't reach absorbing nodes, handled as in UnreachablePolicy
+	DroppedMass  float64               // total probability mass of the entries discarded by WithPruning
+	ArtifactsDir string                // directory of the solver files kept by WithArtifactsDir
+}
+
+// DivergenceError is returned when a solve doesn't converge.
+type DivergenceError struct {
+	Target string // the quantity computed by the solve, such as "absorption probabilities into 3"
+	Node   uint32 // the node the solve refers to, such as the absorbing node or group for absorption probabilities, zero if none
+	SolveStats
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("AbsorbingMarkovChain Error: solve of %v diverged with reason %v after %v iterations, residual norm %v.", e.Target, e.Reason, e.Iterations, e.Residual)
+}
+
+// AmbiguityError is returned when some transient nodes can't be assigned, because of tied absorption probabilities.
+type AmbiguityError struct {
+	Nodes *roaring.Bitmap
+}
+
+func (e *AmbiguityError) Error() string {
+	v, _ := e.Nodes.Select(0)
+	return fmt.Sprintf("AbsorbingMarkovChain Error: %v transient nodes have tied absorption probabilities, such as %v.", e.Nodes.GetCardinality(), v)
+}
@@ -0,0 +1,245 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// Results represents the absorption probabilities of an absorbing markov chain, enumerable in original ID order.
+type Results struct {
+	Report         SolveReport
+	Metadata       Metadata
+	probabilities  probabilities
+	ttn, tan       translator
+	absorbingNodes *roaring.Bitmap //of the chain, targets or not
+}
+
+// AbsorptionResults calculates absorption probabilities for the current absorbing markov chain, as AbsorptionProbabilities,
+// returning them as Results.
+func (chain *AbsorbingMarkovChain) AbsorptionResults(ctx context.Context) (results *Results, err error) {
+	return chain.absorptionProbabilities(ctx, nil, nil, func() { chain = nil }) //enable eventual GC
+}
+
+// Metadata describes the computation of Results.
+type Metadata struct {
+	SolverOptions  SolverOptions // with defaults applied
+	Nodes          uint64        // number of nodes of the chain
+	AbsorbingNodes uint64        // number of absorbing nodes of the chain
+	SystemHash     uint64        // FNV-1a hash of the solved linear system, along with its node IDs
+}
+
+// Weight returns the probability of absorption of the transient node from into the absorbing node to.
+func (r *Results) Weight(from, to uint32) (weight float64, err error) {
+	a, e1 := r.target(to)
+	t, e2 := r.ttn.ToNew(from)
+	switch {
+	case e1 != nil:
+		err = e1
+	case e2 != nil:
+		err = e2
+	default:
+		weight = r.probabilities.at(int(a), int(t))
+	}
+	return
+}
+
+// target translates an absorbing node to its index among the targets.
+func (r *Results) target(absorbing uint32) (a uint32, err error) {
+	if a, err = r.tan.ToNew(absorbing); err != nil && r.absorbingNodes.Contains(absorbing) {
+		err = errors.Errorf("AbsorbingMarkovChain Error: absorption probabilities into %v were not computed, as it is not a target.", absorbing)
+	}
+	return
+}
+
+// Each calls f for each transient and absorbing node pair, by increasing transient node and then by increasing absorbing
+// node, until f returns false. With WithPruning, only the retained pairs are enumerated.
+func (r *Results) Each(f func(transient, absorbing uint32, p float64) bool) (err error) {
+	for t := 0; t < r.probabilities.nT; t++ {
+		transient, err := r.ttn.ToOld(uint32(t))
+		if err != nil {
+			return err
+		}
+		ok := true
+		r.probabilities.each(t, func(a int, w float64) {
+			if !ok || err != nil {
+				return
+			}
+			var absorbing uint32
+			if absorbing, err = r.tan.ToOld(uint32(a)); err == nil {
+				ok = f(transient, absorbing, w)
+			}
+		})
+		if err != nil || !ok {
+			return err
+		}
+	}
+	return
+}
+
+// Transient returns the absorption probabilities of the transient node, sorted by absorbing node.
+// With WithPruning, only the retained ones are returned.
+func (r *Results) Transient(transient uint32) (probabilities []NodeWeight, err error) {
+	t, err := r.ttn.ToNew(transient)
+	if err != nil {
+		return nil, err
+	}
+	probabilities = []NodeWeight{}
+	r.probabilities.each(int(t), func(a int, w float64) {
+		if err != nil {
+			return
+		}
+		var absorbing uint32
+		if absorbing, err = r.tan.ToOld(uint32(a)); err == nil {
+			probabilities = append(probabilities, NodeWeight{absorbing, w})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Absorbing returns the probabilities of absorption into the absorbing node, sorted by transient node.
+// With WithPruning, only the retained ones are returned.
+func (r *Results) Absorbing(absorbing uint32) (probabilities []NodeWeight, err error) {
+	a, err := r.target(absorbing)
+	if err != nil {
+		return nil, err
+	}
+	probabilities = []NodeWeight{}
+	for t := 0; t < r.probabilities.nT; t++ {
+		w := r.probabilities.at(int(a), t)
+		if r.probabilities.dense == nil && w == 0 { //pruned
+			continue
+		}
+		transient, err := r.ttn.ToOld(uint32(t))
+		if err != nil {
+			return nil, err
+		}
+		probabilities = append(probabilities, NodeWeight{transient, w})
+	}
+	return
+}
+
+// Assignments calculates a majority assignment, as AbsorptionAssignments, breaking ties with the given policy and source
+// of randomness, the global source of math/rand if nil.
+func (r *Results) Assignments(tieBreaking TieBreaking, rnd *rand.Rand) (assigner map[uint32]uint32, err error) {
+	fail := func(e error) (map[uint32]uint32, error) {
+		assigner, err = nil, e
+		return assigner, err
+	}
+
+	intn := rand.Intn
+	if rnd != nil {
+		intn = rnd.Intn
+	}
+	fuzzyAssignments, ttn, tan := r.probabilities, r.ttn, r.tan
+
+	silentFail := func(fi func(uint32) (uint32, error)) func(int) uint32 {
+		return func(intida int) (idb uint32) {
+			ida := uint32(intida)
+			switch {
+			case err != nil:
+				//Skip it
+			case ida > ^uint32(0): //max Uint32
+				err = errors.Errorf("%v is not a valid node.", ida)
+			default:
+				idb, err = fi(ida)
+			}
+			return
+		}
+	}
+	ttn2Old := silentFail(ttn.ToOld)
+	tan2Old := silentFail(tan.ToOld)
+
+	ambiguous := roaring.NewBitmap()
+	assigner = make(map[uint32]uint32, fuzzyAssignments.nT)
+	for tnID := 0; tnID < fuzzyAssignments.nT; tnID++ {
+		bestv, bestw, ties := -1, -1.0, 0
+		fuzzyAssignments.each(tnID, func(v int, w float64) {
+			switch {
+			case w > bestw:
+				bestv, bestw, ties = v, w, 1
+			case w < bestw:
+				//do nothing
+			case tieBreaking == TieHighestID:
+				bestv, ties = v, ties+1
+			case tieBreaking == TieRandom:
+				if ties++; intn(ties) == 0 { //reservoir sampling among tied nodes
+					bestv = v
+				}
+			default:
+				ties++
+			}
+		})
+		switch {
+		case bestv == -1:
+			//all entries pruned, leave it unassigned
+			continue
+		case ties > 1 && tieBreaking == TieAmbiguous:
+			ambiguous.Add(ttn2Old(tnID))
+			continue
+		}
+		assigner[ttn2Old(tnID)] = tan2Old(bestv)
+	}
+
+	if err != nil {
+		return fail(err)
+	}
+
+	if !ambiguous.IsEmpty() {
+		err = &AmbiguityError{ambiguous}
+	}
+
+	return
+}
+
+// TopK calculates, for each transient node, the k absorbing nodes with highest absorption probabilities, as AbsorptionTopK.
+func (r *Results) TopK(k int) (topK map[uint32][]NodeWeight, err error) {
+	fail := func(e error) (map[uint32][]NodeWeight, error) {
+		topK, err = nil, e
+		return topK, err
+	}
+
+	if k < 1 {
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: %v is not a valid k.", k))
+	}
+	fuzzyAssignments, ttn, tan := r.probabilities, r.ttn, r.tan
+
+	if fuzzyAssignments.nA < k {
+		k = fuzzyAssignments.nA
+	}
+	topK = map[uint32][]NodeWeight{}
+	for tnID := 0; tnID < fuzzyAssignments.nT; tnID++ {
+		best := make([]NodeWeight, 0, k+1) //new IDs
+		fuzzyAssignments.each(tnID, func(v int, w float64) {
+			p := sort.Search(len(best), func(i int) bool { return best[i].Weight < w })
+			if p == k {
+				return
+			}
+			best = append(best, NodeWeight{})
+			copy(best[p+1:], best[p:])
+			best[p] = NodeWeight{uint32(v), w}
+			if len(best) > k {
+				best = best[:k]
+			}
+		})
+
+		tn, err := ttn.ToOld(uint32(tnID))
+		if err != nil {
+			return fail(err)
+		}
+		for i, nw := range best {
+			if best[i].Node, err = tan.ToOld(nw.Node); err != nil {
+				return fail(err)
+			}
+		}
+		topK[tn] = best
+	}
+
+	return
+}
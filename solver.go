@@ -0,0 +1,193 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/ebonetti/absorbingmarkovchain/internal/gmres"
+)
+
+// SparseMatrix represents a square sparse matrix in compressed sparse row format: the column indices
+// and the values of the i-th row are Cols[RowPtr[i]:RowPtr[i+1]] and Values[RowPtr[i]:RowPtr[i+1]].
+type SparseMatrix struct {
+	RowPtr []int
+	Cols   []uint32
+	Values []float64
+}
+
+// Size returns the number of rows of the matrix.
+func (A *SparseMatrix) Size() int {
+	return gmres.Matrix(*A).Size()
+}
+
+// Transpose returns the transpose of the matrix.
+func (A *SparseMatrix) Transpose() *SparseMatrix {
+	n := A.Size()
+	T := &SparseMatrix{RowPtr: make([]int, n+1), Cols: make([]uint32, len(A.Cols)), Values: make([]float64, len(A.Values))}
+	for _, j := range A.Cols {
+		T.RowPtr[j+1]++
+	}
+	for i := 0; i < n; i++ {
+		T.RowPtr[i+1] += T.RowPtr[i]
+	}
+	next := append([]int{}, T.RowPtr[:n]...)
+	for i := 0; i < n; i++ {
+		for p := A.RowPtr[i]; p < A.RowPtr[i+1]; p++ {
+			j := A.Cols[p]
+			T.Cols[next[j]] = uint32(i)
+			T.Values[next[j]] = A.Values[p]
+			next[j]++
+		}
+	}
+	return T
+}
+
+// Solver solves the linear systems of an absorbing markov chain.
+type Solver interface {
+	// Solve solves A·x = b for each right hand side b in B, where A is the (I-Q) matrix restricted to
+	// the transient nodes, and returns the solutions and their outcomes in the same order. Solvers ignore
+	// the options that don't apply to them. A diverged solve is reported in its stats, not as an error.
+	Solve(ctx context.Context, A *SparseMatrix, B [][]float64, o SolverOptions) (X [][]float64, stats []SolveStats, err error)
+}
+
+// SolverOptions represents the settings of the iterative solvers, zero values stand for the defaults in DefaultSolverOptions.
+type SolverOptions struct {
+	RTol, ATol, DTol float64  // relative, absolute and divergence tolerances of the residual norm, ZeroTol for 0
+	MaxIterations    int      // maximum number of iterations
+	Restart          int      // number of iterations before GMRES restarts
+	KSPType          string   // PETSc Krylov method, GMRESSolver supports only "gmres"
+	PCType           string   // PETSc preconditioner, GMRESSolver supports "sor", "jacobi" and "none"
+	PETScOptions     []string // further PETSc command line options, such as "-ksp_monitor"
+}
+
+// ZeroTol, as any negative value, sets a tolerance of SolverOptions to 0, since the zero value stands for the default.
+const ZeroTol = -1.0
+
+// DefaultSolverOptions are the default settings of the iterative solvers.
+var DefaultSolverOptions = SolverOptions{RTol: 1e-8, ATol: 1e-16, DTol: 1e4, MaxIterations: 500, Restart: 30, KSPType: "gmres", PCType: "sor"}
+
+func (o SolverOptions) withDefaults() SolverOptions {
+	d := DefaultSolverOptions
+	for _, p := range []struct{ v, d *float64 }{{&o.RTol, &d.RTol}, {&o.ATol, &d.ATol}, {&o.DTol, &d.DTol}} {
+		switch {
+		case *p.v == 0:
+			*p.v = *p.d
+		case *p.v < 0:
+			*p.v = 0
+		}
+	}
+	for _, p := range []struct{ v, d *int }{{&o.MaxIterations, &d.MaxIterations}, {&o.Restart, &d.Restart}} {
+		if *p.v == 0 {
+			*p.v = *p.d
+		}
+	}
+	for _, p := range []struct{ v, d *string }{{&o.KSPType, &d.KSPType}, {&o.PCType, &d.PCType}} {
+		if *p.v == "" {
+			*p.v = *p.d
+		}
+	}
+	return o
+}
+
+func (o SolverOptions) petscArgs() (args []string) {
+	o = o.withDefaults()
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	args = []string{
+		"-ksp_rtol", f(o.RTol),
+		"-ksp_atol", f(o.ATol),
+		"-ksp_divtol", f(o.DTol),
+		"-ksp_max_it", strconv.Itoa(o.MaxIterations),
+		"-ksp_gmres_restart", strconv.Itoa(o.Restart),
+		"-ksp_type", o.KSPType,
+		"-pc_type", o.PCType,
+	}
+	return append(args, o.PETScOptions...)
+}
+
+// DefaultSolver returns a PETScSolver using tmpDir if PETSc is available, a GMRESSolver otherwise.
+func DefaultSolver(tmpDir string) Solver {
+	if gmres.Available() {
+		return PETScSolver{TmpDir: tmpDir}
+	}
+	return GMRESSolver{}
+}
+
+// PETScSolver solves the linear systems with the GMRES solver of PETSc, storing its temporary files in TmpDir.
+// If WorkDir is set, the files are written there instead, along with the solver sources, and left in place.
+type PETScSolver struct {
+	TmpDir  string
+	WorkDir string
+}
+
+// Solve solves A·x = b for each right hand side b in B.
+func (s PETScSolver) Solve(ctx context.Context, A *SparseMatrix, B [][]float64, o SolverOptions) (X [][]float64, stats []SolveStats, err error) {
+	fail := func(e error) ([][]float64, []SolveStats, error) {
+		X, stats, err = nil, nil, e
+		return X, stats, err
+	}
+
+	tmpDir, keep := s.WorkDir, s.WorkDir != ""
+	switch {
+	case keep:
+		if err = os.MkdirAll(tmpDir, 0755); err != nil {
+			return fail(errors.Wrapf(err, "AbsorbingMarkovChain Error: unable to create the work directory %v.", tmpDir))
+		}
+	default:
+		if tmpDir, err = ioutil.TempDir(s.TmpDir, "."); err != nil {
+			return fail(errors.Wrap(err, "AbsorbingMarkovChain Error: unable to create a temporary directory."))
+		}
+		defer os.RemoveAll(tmpDir)
+	}
+	solverInfile := filepath.Join(tmpDir, "Ab.ptsc")
+	solverOutfile := filepath.Join(tmpDir, "sol.ptsc")
+	solverReportfile := filepath.Join(tmpDir, "report.txt")
+
+	//transform system to Ab.petsc
+	if err = graph2Petsc(A, B, solverInfile); err != nil {
+		return fail(err)
+	}
+
+	//run solver
+	if err = gmres.Run(ctx, solverInfile, solverOutfile, solverReportfile, tmpDir, o.petscArgs(), keep); err != nil {
+		return fail(err)
+	}
+
+	//transform back from sol.ptsc
+	if X, err = petsc2Assignments(solverOutfile); err != nil {
+		return fail(err)
+	}
+	if stats, err = petsc2Stats(solverReportfile); err != nil {
+		return fail(err)
+	}
+	if len(X) != len(B) || len(stats) != len(B) {
+		return fail(errors.Errorf("AbsorbingMarkovChain Error: %v solutions and %v reports for %v right hand sides.", len(X), len(stats), len(B)))
+	}
+
+	return
+}
+
+// GMRESSolver solves the linear systems with a pure Go implementation of the restarted GMRES method.
+type GMRESSolver struct{}
+
+// Solve solves A·x = b for each right hand side b in B.
+func (GMRESSolver) Solve(ctx context.Context, A *SparseMatrix, B [][]float64, o SolverOptions) (X [][]float64, stats []SolveStats, err error) {
+	if o = o.withDefaults(); o.KSPType != "gmres" {
+		return nil, nil, errors.Errorf("AbsorbingMarkovChain Error: unsupported Krylov method %v.", o.KSPType)
+	}
+	gmresOptions := gmres.Options{RTol: o.RTol, ATol: o.ATol, DTol: o.DTol, MaxIterations: o.MaxIterations, Restart: o.Restart, PCType: o.PCType}
+
+	X, stats = make([][]float64, len(B)), make([]SolveStats, len(B))
+	for i, b := range B {
+		var s gmres.Stats
+		if X[i], s, err = gmres.Solve(ctx, gmres.Matrix(*A), b, gmresOptions); err != nil {
+			return nil, nil, err
+		}
+		stats[i] = SolveStats{ConvergedReason(s.Reason), s.Iterations, s.Residual}
+	}
+	return
+}
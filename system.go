@@ -0,0 +1,51 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+)
+
+// linearSystem represents the (I-Q) system of a chain, along with the solver configured for it.
+type linearSystem struct {
+	A       *SparseMatrix
+	ttn     translator
+	solver  Solver
+	options SolverOptions
+	workDir string //if set, the system files are kept there
+}
+
+func (chain *AbsorbingMarkovChain) linearSystem(A *SparseMatrix, ttn translator) linearSystem {
+	solver := chain.solver
+	if solver == nil {
+		solver = DefaultSolver(chain.tmpDir)
+	}
+	return linearSystem{A: A, ttn: ttn, solver: solver, options: chain.solverOptions}
+}
+
+// solve solves the system for each right hand side in B, failing if any solve diverges;
+// diverged returns the error describing the divergence of the i-th right hand side.
+func (s linearSystem) solve(ctx context.Context, B [][]float64, diverged func(i int) *DivergenceError) (X [][]float64, stats []SolveStats, err error) {
+	solver := s.solver
+	if s.workDir != "" {
+		switch ps, ok := solver.(PETScSolver); {
+		case ok: //it writes the system files by itself
+			ps.WorkDir = s.workDir
+			solver = ps
+		default:
+			if err = writeArtifactsBatch(s.workDir, s.A, B); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if X, stats, err = solver.Solve(ctx, s.A, B, s.options); err != nil {
+		return nil, nil, err
+	}
+	for i, st := range stats {
+		if !st.Reason.Converged() {
+			e := diverged(i)
+			e.SolveStats = st
+			return nil, nil, e
+		}
+	}
+	return
+}
@@ -0,0 +1,252 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// ProblemKind represents the kind of a problem of an absorbing markov chain.
+type ProblemKind int
+
+// Problem kinds.
+const (
+	DanglingArc          ProblemKind = iota + 1 // an arc towards a node that isn't in the graph
+	InvalidAbsorbingNode                        // an absorbing node that isn't in the graph or that has arcs towards other nodes
+	UnreachableNode                             // a transient node that can't reach any absorbing node
+	BadWeight                                   // an arc whose weight is not positive, infinite, NaN or can't be retrieved
+)
+
+// Problem represents a problem of an absorbing markov chain.
+type Problem struct {
+	Kind   ProblemKind
+	From   uint32  // the node affected by the problem, or the source of the affected arc
+	To     uint32  // the target of the affected arc, for DanglingArc and BadWeight
+	Weight float64 // the weight of the affected arc, for BadWeight
+	Err    error   // the error returned by the weighter, for BadWeight
+}
+
+func (p Problem) Error() string {
+	switch p.Kind {
+	case DanglingArc:
+		return fmt.Sprintf("arc (%v,%v) shouldn't exist: %v isn't a graph node.", p.From, p.To, p.To)
+	case InvalidAbsorbingNode:
+		return fmt.Sprintf("%v is not a valid absorbing node.", p.From)
+	case UnreachableNode:
+		return fmt.Sprintf("%v isn't transient node, neither it's declared absorbing.", p.From)
+	case BadWeight:
+		switch {
+		case p.Err != nil:
+			return p.Err.Error()
+		case math.IsInf(p.Weight, 0):
+			return fmt.Sprintf("arc (%v,%v) has infinite weight (%v).", p.From, p.To, p.Weight)
+		case math.IsNaN(p.Weight):
+			return fmt.Sprintf("arc (%v,%v) has NaN weight (%v).", p.From, p.To, p.Weight)
+		default:
+			return fmt.Sprintf("arc (%v,%v) hasn't positive weight (%v).", p.From, p.To, p.Weight)
+		}
+	}
+	return fmt.Sprintf("unknown problem %v on %v.", p.Kind, p.From)
+}
+
+// ValidationReport represents the problems of an absorbing markov chain.
+type ValidationReport struct {
+	Problems []Problem
+}
+
+// Err returns the first problem of the report, nil if there are none.
+func (r ValidationReport) Err() error {
+	if len(r.Problems) == 0 {
+		return nil
+	}
+	return r.Problems[0]
+}
+
+// Validate checks the absorbing markov chain and the weights of all its arcs, collecting all the problems found.
+// The returned error is not nil only if the validation couldn't be completed.
+func (chain *AbsorbingMarkovChain) Validate(ctx context.Context) (report ValidationReport, err error) {
+	if chain == nil {
+		return report, errors.New("AbsorbingMarkovChain Error: nil chain")
+	}
+	_, err = chain.validate(ctx, &report, true)
+	return
+}
+
+// validate appends the problems of the chain to the report, returning the nodes that reach an absorbing node.
+func (chain *AbsorbingMarkovChain) validate(ctx context.Context, report *ValidationReport, checkWeights bool) (reaching *roaring.Bitmap, err error) {
+	add := func(p Problem) {
+		report.Problems = append(report.Problems, p)
+	}
+
+	if err = chain.checkGraphNodes(ctx, add, checkWeights); err != nil {
+		return
+	}
+
+	reaching = roaring.NewBitmap()
+	chain.checkAbsorbingNodes(reaching, add)
+
+	if err = chain.checkTransientNodes(ctx, reaching); err != nil {
+		return
+	}
+	for i := roaring.AndNot(chain.Nodes, reaching).Iterator(); i.HasNext(); {
+		if v := i.Next(); !chain.absorbingNodes.Contains(v) {
+			add(Problem{Kind: UnreachableNode, From: v})
+		}
+	}
+
+	return
+}
+
+func (chain *AbsorbingMarkovChain) checkRequirements() (checked *AbsorbingMarkovChain, err error) { //for absorbing markov chain
+	if chain == nil {
+		return nil, errors.New("AbsorbingMarkovChain Error: nil chain")
+	}
+
+	var report ValidationReport
+	reaching, err := chain.validate(context.Background(), &report, false)
+	if err != nil {
+		return
+	}
+	for _, p := range report.Problems {
+		if p.Kind != UnreachableNode || chain.unreachablePolicy == FailUnreachable {
+			return nil, p
+		}
+	}
+
+	c := *chain
+	c.Weighter = checkedWeighter(chain.Weighter)
+	c.unreachable = roaring.AndNot(roaring.AndNot(chain.Nodes, reaching), chain.absorbingNodes)
+	switch {
+	case c.unreachable.IsEmpty():
+		//nothing to do
+	case chain.unreachablePolicy == DropUnreachable:
+		c.dGraph = c.dGraph.filterNodes(c.unreachable)
+	case chain.unreachablePolicy == AbsorbUnreachable:
+		edges, unreachable := chain.Edges, c.unreachable
+		c.absorbingNodes = roaring.Or(chain.absorbingNodes, unreachable)
+		c.Edges = func(from uint32) (to []uint32) {
+			if unreachable.Contains(from) {
+				return nil
+			}
+			return edges(from)
+		}
+	}
+
+	return &c, nil
+}
+
+// checkArtifactsDir rejects WithArtifactsDir in the computations other than absorption probabilities, as their
+// systems are not kept nor replayed.
+func (chain *AbsorbingMarkovChain) checkArtifactsDir() error {
+	if chain.artifactsDir != "" {
+		return errors.New("AbsorbingMarkovChain Error: WithArtifactsDir only applies to absorption probabilities")
+	}
+	return nil
+}
+
+// checkSinks rejects the unassigned sinks made by AbsorbUnreachable in the computations of absorption times and
+// visits, that are infinite from the nodes that may enter them.
+func (chain *AbsorbingMarkovChain) checkSinks() error {
+	if chain.unreachablePolicy == AbsorbUnreachable && chain.unreachable != nil && !chain.unreachable.IsEmpty() {
+		v, _ := chain.unreachable.Select(0)
+		return errors.Errorf("AbsorbingMarkovChain Error: %v is an unassigned sink, the chain is not absorbing.", v)
+	}
+	return nil
+}
+
+// assignableNodes returns the absorbing nodes of the chain, except for the unreachable nodes made absorbing by
+// AbsorbUnreachable: those are unassigned sinks, never targets.
+func (chain *AbsorbingMarkovChain) assignableNodes() *roaring.Bitmap {
+	if chain.unreachable == nil {
+		return chain.absorbingNodes
+	}
+	return roaring.AndNot(chain.absorbingNodes, chain.unreachable)
+}
+
+func (chain *AbsorbingMarkovChain) checkGraphNodes(ctx context.Context, add func(Problem), checkWeights bool) (err error) {
+	nodes, weighter := chain.Nodes, checkedWeighter(chain.Weighter)
+	for i := nodes.Iterator(); i.HasNext(); {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		from := i.Next()
+		to := chain.Edges(from)
+		for _, id := range to {
+			switch {
+			case !nodes.Contains(id):
+				add(Problem{Kind: DanglingArc, From: from, To: id})
+			case checkWeights:
+				if w, e := weighter(from, id); e != nil {
+					p := Problem{Kind: BadWeight, From: from, To: id, Weight: w}
+					if _, ok := e.(weightError); !ok {
+						p.Err = e
+					}
+					add(p)
+				}
+			}
+		}
+	}
+	return
+}
+
+func (chain *AbsorbingMarkovChain) checkAbsorbingNodes(nodes *roaring.Bitmap, add func(Problem)) {
+	for i := chain.absorbingNodes.Iterator(); i.HasNext(); {
+		ANode := i.Next()
+		to := chain.Edges(ANode)
+		switch {
+		case !chain.Nodes.Contains(ANode):
+			fallthrough
+		case len(to) > 1:
+			fallthrough
+		case len(to) == 1 && to[0] != ANode:
+			add(Problem{Kind: InvalidAbsorbingNode, From: ANode})
+		default:
+			nodes.Add(ANode)
+		}
+	}
+}
+
+func (chain *AbsorbingMarkovChain) checkTransientNodes(ctx context.Context, nodes *roaring.Bitmap) (err error) {
+	changed := true
+	for changed {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		changed = false
+		for i := roaring.AndNot(chain.Nodes, chain.absorbingNodes).Iterator(); i.HasNext(); {
+			from := i.Next()
+			if !nodes.Contains(from) {
+				to := chain.Edges(from)
+				for _, id := range to {
+					if nodes.Contains(id) {
+						nodes.Add(from)
+						changed = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// weightError is the error returned by checkedWeighter for invalid weights.
+type weightError struct {
+	Problem
+}
+
+func checkedWeighter(weighter func(from, to uint32) (weight float64, err error)) func(from, to uint32) (weight float64, err error) {
+	return func(from, to uint32) (weight float64, err error) {
+		weight, err = weighter(from, to)
+		switch {
+		case err != nil:
+			//err already set
+		case weight <= 0, math.IsInf(weight, 0), math.IsNaN(weight):
+			err = weightError{Problem{Kind: BadWeight, From: from, To: to, Weight: weight}}
+		}
+		return
+	}
+}
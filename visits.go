@@ -0,0 +1,92 @@
+package absorbingmarkovchain
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+)
+
+// NodeWeight represents a node along with an associated weight, such as a probability or an expected count.
+type NodeWeight struct {
+	Node   uint32
+	Weight float64
+}
+
+// ExpectedVisits calculates, for each transient node in sources, the expected number of visits to each transient node
+// before being absorbed, that is the row of the fundamental matrix N = (I-Q)^-1 associated to the source.
+// Sources are all the transient nodes if nil, an empty set makes the computation fail.
+// Only the visits counts above threshold are returned, sorted by node.
+func (chain *AbsorbingMarkovChain) ExpectedVisits(ctx context.Context, sources *roaring.Bitmap, threshold float64) (visits map[uint32][]NodeWeight, err error) {
+	fail := func(e error) (map[uint32][]NodeWeight, error) {
+		visits, err = nil, e
+		return visits, err
+	}
+
+	if chain, err = chain.checkRequirements(); err != nil {
+		return fail(err)
+	}
+	if err = chain.checkArtifactsDir(); err != nil {
+		return fail(err)
+	}
+	if err = chain.checkSinks(); err != nil {
+		return fail(err)
+	}
+	switch {
+	case sources == nil:
+		sources = roaring.AndNot(chain.Nodes, chain.absorbingNodes)
+	case sources.IsEmpty():
+		return fail(errors.New("AbsorbingMarkovChain Error: no source nodes"))
+	}
+
+	//transform wikigraph to the transposed linear system
+	A, ttn, _, err := graph2Matrix(chain)
+	if err != nil {
+		return fail(err)
+	}
+	n := A.Size()
+	B := make([][]float64, 0, sources.GetCardinality())
+	for i := sources.Iterator(); i.HasNext(); {
+		source := i.Next()
+		t, err := ttn.ToNew(source)
+		if err != nil {
+			return fail(errors.Wrapf(err, "AbsorbingMarkovChain Error: %v is not a transient node.", source))
+		}
+		b := make([]float64, n)
+		b[t] = 1
+		B = append(B, b)
+	}
+	system := chain.linearSystem(A.Transpose(), ttn)
+
+	//enable eventual GC
+	chain = nil
+	debug.FreeOSMemory()
+
+	sourceIDs := sources.ToArray()
+	X, _, err := system.solve(ctx, B, func(i int) *DivergenceError {
+		return &DivergenceError{Target: fmt.Sprint("expected visits from ", sourceIDs[i]), Node: sourceIDs[i]}
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	visits = make(map[uint32][]NodeWeight, len(X))
+	for i, x := range X {
+		row := []NodeWeight{}
+		for t, v := range x {
+			if v <= threshold {
+				continue
+			}
+			node, err := ttn.ToOld(uint32(t))
+			if err != nil {
+				return fail(err)
+			}
+			row = append(row, NodeWeight{node, v})
+		}
+		visits[sourceIDs[i]] = row
+	}
+
+	return
+}